@@ -7,7 +7,7 @@ func CreateActivity(o *Object) *Object {
 	}
 	obj := make(map[string]interface{})
 	for k, v := range o.data {
-		if createActivityAttrs[k] {
+		if activityAudienceAttrs[k] || createActivityAttrs[k] {
 			act[k] = v
 		}
 		if createActivityIgnored[k] {
@@ -20,15 +20,239 @@ func CreateActivity(o *Object) *Object {
 	return New(act)
 }
 
+// FollowActivity returns a Follow of the given actor, addressed to them.
+func FollowActivity(actor string, o *Object) *Object {
+	act := newActivity("Follow", actor, o)
+	act.data["object"] = objectRef(o)
+	act.data["to"] = []interface{}{o.ID()}
+	return act
+}
+
+// AcceptActivity returns an Accept of the given activity, such as a Follow,
+// addressed to its actor.
+func AcceptActivity(actor string, o *Object) *Object {
+	return responseActivity("Accept", actor, o)
+}
+
+// RejectActivity returns a Reject of the given activity, such as a Follow,
+// addressed to its actor.
+func RejectActivity(actor string, o *Object) *Object {
+	return responseActivity("Reject", actor, o)
+}
+
+// UndoActivity returns an Undo of the given activity, with its audience.
+func UndoActivity(actor string, o *Object) *Object {
+	act := newActivity("Undo", actor, o)
+	copyAudience(act, o)
+	act.data["object"] = embedObject(o)
+	return act
+}
+
+// LikeActivity returns a Like of the given object, addressed to its author.
+func LikeActivity(actor string, o *Object) *Object {
+	act := newActivity("Like", actor, o)
+	act.data["object"] = objectRef(o)
+	if to := idList(o.AttributedTo()); len(to) > 0 {
+		act.data["to"] = to
+	}
+	return act
+}
+
+// AnnounceActivity returns a public Announce of the given object, addressed to
+// the announcing actor's followers collection and the object's author.
+func AnnounceActivity(actor, followers string, o *Object) *Object {
+	act := newActivity("Announce", actor, o)
+	act.data["object"] = objectRef(o)
+	act.data["to"] = []interface{}{Public}
+
+	var cc []string
+	if len(followers) > 0 {
+		cc = append(cc, followers)
+	}
+	cc = append(cc, o.AttributedTo()...)
+	if len(cc) > 0 {
+		act.data["cc"] = idList(cc)
+	}
+	return act
+}
+
+// UpdateActivity returns an Update that embeds the given object, with its
+// audience.
+func UpdateActivity(actor string, o *Object) *Object {
+	act := newActivity("Update", actor, o)
+	copyAudience(act, o)
+	act.data["object"] = embedObject(o)
+	return act
+}
+
+// DeleteActivity returns a Delete of the given object, with its audience.
+func DeleteActivity(actor string, o *Object) *Object {
+	return referenceActivity("Delete", actor, o)
+}
+
+// BlockActivity returns a Block of the given actor, addressed to them.
+func BlockActivity(actor string, o *Object) *Object {
+	act := newActivity("Block", actor, o)
+	act.data["object"] = objectRef(o)
+	act.data["to"] = []interface{}{o.ID()}
+	return act
+}
+
+// FlagActivity returns a Flag of the given objects, such as an actor and some
+// of their posts.
+func FlagActivity(actor string, objs ...*Object) *Object {
+	act := newActivity("Flag", actor, nil)
+	refs := make([]interface{}, len(objs))
+	for i, o := range objs {
+		refs[i] = objectRef(o)
+	}
+	act.data["object"] = refs
+	return act
+}
+
+// AddActivity returns an Add of the given object to the target collection,
+// with the object's audience.
+func AddActivity(actor string, o, target *Object) *Object {
+	act := referenceActivity("Add", actor, o)
+	act.data["target"] = objectRef(target)
+	return act
+}
+
+// RemoveActivity returns a Remove of the given object from the target
+// collection, with the object's audience.
+func RemoveActivity(actor string, o, target *Object) *Object {
+	act := referenceActivity("Remove", actor, o)
+	act.data["target"] = objectRef(target)
+	return act
+}
+
+// MoveActivity returns a Move of the given object, usually the actor, to the
+// target, such as the actor's new account.
+func MoveActivity(actor string, o, target *Object) *Object {
+	act := referenceActivity("Move", actor, o)
+	act.data["target"] = objectRef(target)
+	return act
+}
+
+// QuestionActivity returns a Question with the properties of the given object,
+// such as its content and endTime, but not its id or actor. The options are set
+// as anyOf if multiple choices are allowed, or oneOf.
+func QuestionActivity(actor string, o *Object, multiple bool, options ...string) *Object {
+	act := newActivity("Question", actor, o)
+	for k, v := range o.data {
+		if !createActivityIgnored[k] && !questionIgnored[k] {
+			act.data[k] = v
+		}
+	}
+
+	choices := make([]interface{}, len(options))
+	for i, opt := range options {
+		choices[i] = map[string]interface{}{
+			"type": "Note",
+			"name": opt,
+			"replies": map[string]interface{}{
+				"type":       "Collection",
+				"totalItems": float64(0),
+			},
+		}
+	}
+
+	if multiple {
+		act.data["anyOf"] = choices
+	} else {
+		act.data["oneOf"] = choices
+	}
+	return act
+}
+
+func newActivity(ty, actor string, o *Object) *Object {
+	act := New(map[string]interface{}{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"type":     ty,
+		"actor":    actor,
+	})
+	if o != nil {
+		act.lang = o.lang
+	}
+	return act
+}
+
+// responseActivity returns an Accept or Reject of an activity.
+func responseActivity(ty, actor string, o *Object) *Object {
+	act := newActivity(ty, actor, o)
+	act.data["object"] = embedObject(o)
+	if ids := o.IDs("actor"); len(ids) > 0 {
+		act.data["to"] = idList(ids)
+	}
+	return act
+}
+
+// referenceActivity returns an activity that refers to the object by its id,
+// with the object's audience.
+func referenceActivity(ty, actor string, o *Object) *Object {
+	act := newActivity(ty, actor, o)
+	copyAudience(act, o)
+	act.data["object"] = objectRef(o)
+	return act
+}
+
+// copyAudience copies the object's audience to the activity, without its bto
+// and bcc, which are only for the object's own delivery.
+func copyAudience(act, o *Object) {
+	for k, v := range o.data {
+		if activityAudienceAttrs[k] && k != "bto" && k != "bcc" {
+			act.data[k] = v
+		}
+	}
+}
+
+func idList(ids []string) []interface{} {
+	list := make([]interface{}, len(ids))
+	for i, id := range ids {
+		list[i] = id
+	}
+	return list
+}
+
+// objectRef returns the id of the object, or the object itself if it has no
+// id.
+func objectRef(o *Object) interface{} {
+	if id := o.ID(); len(id) > 0 {
+		return id
+	}
+	return embedObject(o)
+}
+
+// embedObject returns the object's data without its @context, to embed in an
+// activity.
+func embedObject(o *Object) map[string]interface{} {
+	obj := make(map[string]interface{}, len(o.data))
+	for k, v := range o.data {
+		if !createActivityIgnored[k] {
+			obj[k] = v
+		}
+	}
+	return obj
+}
+
+var activityAudienceAttrs = map[string]bool{
+	"audience": true,
+	"bcc":      true,
+	"bto":      true,
+	"cc":       true,
+	"to":       true,
+}
+
 var createActivityAttrs = map[string]bool{
-	"audience":  true,
-	"bcc":       true,
-	"bto":       true,
-	"cc":        true,
-	"to":        true,
 	"published": true,
 }
 
 var createActivityIgnored = map[string]bool{
 	"@context": true,
 }
+
+var questionIgnored = map[string]bool{
+	"actor": true,
+	"id":    true,
+	"type":  true,
+}
@@ -45,3 +45,155 @@ func TestActivityCreate(t *testing.T) {
 	assert.Equal(t, []string{"https://example.com/~erik/followers",
 		"https://www.w3.org/ns/activitystreams#Public"}, note.CC())
 }
+
+func TestActivityConstructors(t *testing.T) {
+	erik := "https://example.com/~erik"
+	bob := Parse(t, `{"id": "https://example.com/~bob", "type": "Person"}`)
+	note := Parse(t, `{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"id": "https://example.com/note/1",
+		"type": "Note",
+		"attributedTo": "https://example.com/~bob",
+		"content": "This is a note",
+		"published": "2015-02-10T15:04:55Z",
+		"to": ["https://www.w3.org/ns/activitystreams#Public"],
+		"cc": ["https://example.com/~bob/followers"],
+		"bcc": ["https://example.com/~secret"]
+	}`)
+
+	t.Run("follow", func(t *testing.T) {
+		follow := apub.FollowActivity(erik, bob)
+		assert.Equal(t, "https://www.w3.org/ns/activitystreams", follow.Str("@context"))
+		assert.Equal(t, "Follow", follow.Type())
+		assert.Equal(t, []string{erik}, follow.IDs("actor"))
+		assert.Equal(t, "https://example.com/~bob", follow.Str("object"))
+		assert.Equal(t, []string{"https://example.com/~bob"}, follow.To())
+
+		follow.SetStr("id", "https://example.com/follow/1")
+		accept := apub.AcceptActivity(bob.ID(), follow)
+		assert.Equal(t, "Accept", accept.Type())
+		assert.Equal(t, []string{"https://example.com/~bob"}, accept.IDs("actor"))
+		assert.Equal(t, []string{erik}, accept.To())
+		assert.Equal(t, "Follow", accept.Object("object").Type())
+		assert.Equal(t, "https://example.com/follow/1", accept.Object("object").ID())
+
+		reject := apub.RejectActivity(bob.ID(), follow)
+		assert.Equal(t, "Reject", reject.Type())
+		assert.Equal(t, []string{"https://example.com/~bob"}, reject.IDs("actor"))
+		assert.Equal(t, []string{erik}, reject.To())
+		assert.Equal(t, "https://example.com/follow/1", reject.Object("object").ID())
+
+		undo := apub.UndoActivity(erik, follow)
+		assert.Equal(t, "Undo", undo.Type())
+		assert.Equal(t, []string{"https://example.com/~bob"}, undo.To())
+		assert.Equal(t, "https://example.com/follow/1", undo.Object("object").ID())
+		assert.Equal(t, "", undo.Object("object").Str("@context"))
+	})
+
+	t.Run("like", func(t *testing.T) {
+		like := apub.LikeActivity(erik, note)
+		assert.Equal(t, "Like", like.Type())
+		assert.Equal(t, []string{erik}, like.IDs("actor"))
+		assert.Equal(t, "https://example.com/note/1", like.Str("object"))
+		assert.Equal(t, []string{"https://example.com/~bob"}, like.To())
+		assert.Equal(t, 0, len(like.CC()))
+		assert.Equal(t, 0, len(like.BCC()))
+		assert.Equal(t, time.Time{}, like.Time("published"))
+	})
+
+	t.Run("announce", func(t *testing.T) {
+		announce := apub.AnnounceActivity(erik, erik+"/followers", note)
+		assert.Equal(t, "Announce", announce.Type())
+		assert.Equal(t, []string{erik}, announce.IDs("actor"))
+		assert.Equal(t, "https://example.com/note/1", announce.Str("object"))
+		assert.Equal(t, []string{"https://www.w3.org/ns/activitystreams#Public"}, announce.To())
+		assert.Equal(t, []string{erik + "/followers", "https://example.com/~bob"}, announce.CC())
+		assert.Equal(t, 0, len(announce.BCC()))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		del := apub.DeleteActivity(erik, note)
+		assert.Equal(t, "Delete", del.Type())
+		assert.Equal(t, []string{erik}, del.IDs("actor"))
+		assert.Equal(t, "https://example.com/note/1", del.Str("object"))
+		assert.Equal(t, []string{"https://www.w3.org/ns/activitystreams#Public"}, del.To())
+		assert.Equal(t, []string{"https://example.com/~bob/followers"}, del.CC())
+		assert.Equal(t, 0, len(del.BCC()))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		update := apub.UpdateActivity(erik, note)
+		assert.Equal(t, "Update", update.Type())
+		assert.Equal(t, []string{"https://example.com/~bob/followers"}, update.CC())
+		assert.Equal(t, 0, len(update.BCC()))
+		obj := update.Object("object")
+		assert.Equal(t, "This is a note", obj.Content(""))
+		assert.Equal(t, "", obj.Str("@context"))
+	})
+
+	t.Run("block and flag", func(t *testing.T) {
+		block := apub.BlockActivity(erik, bob)
+		assert.Equal(t, "Block", block.Type())
+		assert.Equal(t, "https://example.com/~bob", block.Str("object"))
+		assert.Equal(t, []string{"https://example.com/~bob"}, block.To())
+
+		flag := apub.FlagActivity(erik, bob, note)
+		assert.Equal(t, "Flag", flag.Type())
+		assert.Equal(t, []string{"https://example.com/~bob", "https://example.com/note/1"}, flag.IDs("object"))
+		assert.Equal(t, 0, len(flag.To()))
+	})
+
+	t.Run("targets", func(t *testing.T) {
+		featured := Parse(t, `{"id": "https://example.com/~erik/featured", "type": "OrderedCollection"}`)
+		add := apub.AddActivity(erik, note, featured)
+		assert.Equal(t, "Add", add.Type())
+		assert.Equal(t, "https://example.com/note/1", add.Str("object"))
+		assert.Equal(t, "https://example.com/~erik/featured", add.Str("target"))
+		assert.Equal(t, []string{"https://example.com/~bob/followers"}, add.CC())
+		assert.Equal(t, 0, len(add.BCC()))
+
+		remove := apub.RemoveActivity(erik, note, featured)
+		assert.Equal(t, "Remove", remove.Type())
+		assert.Equal(t, "https://example.com/note/1", remove.Str("object"))
+		assert.Equal(t, "https://example.com/~erik/featured", remove.Str("target"))
+
+		move := apub.MoveActivity(bob.ID(), bob, Parse(t, `{"id": "https://example.net/~bob", "type": "Person"}`))
+		assert.Equal(t, "Move", move.Type())
+		assert.Equal(t, "https://example.com/~bob", move.Str("object"))
+		assert.Equal(t, "https://example.net/~bob", move.Str("target"))
+
+		act := apub.LikeActivity(erik, Parse(t, `{"type": "Note", "content": "no id"}`))
+		assert.Equal(t, "no id", act.Object("object").Content(""))
+	})
+
+	t.Run("question", func(t *testing.T) {
+		poll := Parse(t, `{
+			"id": "https://example.com/client/1",
+			"type": "Note",
+			"actor": "https://example.com/~bob",
+			"content": "This is a note",
+			"to": ["https://www.w3.org/ns/activitystreams#Public"]
+		}`)
+		q := apub.QuestionActivity(erik, poll, false, "yes", "no")
+		assert.Equal(t, "Question", q.Type())
+		assert.Equal(t, "", q.ID())
+		assert.Equal(t, []string{erik}, q.IDs("actor"))
+		assert.Equal(t, "This is a note", q.Content(""))
+		assert.Equal(t, "https://www.w3.org/ns/activitystreams", q.Str("@context"))
+		assert.Equal(t, []string{"https://www.w3.org/ns/activitystreams#Public"}, q.To())
+
+		question, ok := apub.AsQuestion(q)
+		if assert.True(t, ok) {
+			options := question.OneOf()
+			if assert.Equal(t, 2, len(options)) {
+				assert.Equal(t, "yes", options[0].Name(""))
+				assert.Equal(t, 0, options[1].Object("replies").Int("totalItems"))
+			}
+			assert.Equal(t, 0, len(question.AnyOf()))
+		}
+
+		q = apub.QuestionActivity(erik, poll, true, "a", "b", "c")
+		assert.Equal(t, 3, len(q.List("anyOf")))
+		assert.Nil(t, q.Errors())
+	})
+}
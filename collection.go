@@ -0,0 +1,166 @@
+package apub
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+)
+
+// CollectionIterator walks the items of a Collection or OrderedCollection,
+// such as an actor's followers or outbox. Inline items are returned first,
+// then the pages are followed from first through next. Pages that are bare
+// IRIs are loaded with the Fetcher.
+//
+//	iter := apub.NewCollectionIterator(ctx, fetcher, outbox)
+//	for iter.Next() {
+//		activity := iter.Item()
+//	}
+//	if err := iter.Err(); err != nil {
+//		...
+//	}
+type CollectionIterator struct {
+	ctx      context.Context
+	fetcher  Fetcher
+	id       string
+	next     *Object
+	items    []*Object
+	item     *Object
+	seen     map[string]bool
+	total    int
+	hasTotal bool
+	err      error
+}
+
+// NewCollectionIterator returns an iterator over the given collection or
+// collection page. If fetcher is nil, the Fetcher of the Parser or Resolver
+// that returned the collection is used.
+func NewCollectionIterator(ctx context.Context, fetcher Fetcher, coll *Object) *CollectionIterator {
+	if fetcher == nil && coll != nil {
+		fetcher = coll.fetcher
+	}
+	it := &CollectionIterator{
+		ctx:     ctx,
+		fetcher: fetcher,
+		seen:    make(map[string]bool),
+	}
+	if coll == nil {
+		return it
+	}
+	it.id = coll.ID()
+
+	if _, _, ok := coll.lookup("totalItems"); ok {
+		total, err := coll.FetchInt("totalItems")
+		it.total, it.hasTotal = total, err == nil
+	}
+
+	if err := it.load(coll); err != nil {
+		it.err = err
+		return it
+	}
+
+	key := "first"
+	if coll.IsType("CollectionPage") {
+		key = "next"
+	}
+	it.next, it.err = coll.FetchObject(key)
+	return it
+}
+
+// Next advances to the next item, fetching the next page if needed. It
+// returns false when the collection is done, or an error stops it.
+func (it *CollectionIterator) Next() bool {
+	it.item = nil
+	for len(it.items) == 0 {
+		if it.err != nil || it.next == nil {
+			return false
+		}
+		if !it.nextPage() {
+			return false
+		}
+	}
+
+	it.item = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+// Item returns the current item. Items that are bare IRIs are returned as
+// objects with only an id, which can be fetched with the Fetcher.
+func (it *CollectionIterator) Item() *Object {
+	return it.item
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *CollectionIterator) Err() error {
+	return it.err
+}
+
+// TotalItems returns the totalItems property of the collection, and whether
+// it was set. It may not match the number of items that are visible.
+func (it *CollectionIterator) TotalItems() (int, bool) {
+	return it.total, it.hasTotal
+}
+
+// nextPage loads the next page. An empty page stops the iterator, so that
+// servers that always link to a next page do not page forever, and so does a
+// page that links back to the collection itself.
+func (it *CollectionIterator) nextPage() bool {
+	page := it.next
+	it.next = nil
+
+	id := page.ID()
+	if len(id) > 0 && id == it.id {
+		return false
+	}
+	if len(id) > 0 && it.seen[id] {
+		it.err = xerrors.Errorf("CollectionIterator: %q: %w", id, ErrCollectionLoop)
+		return false
+	}
+
+	if isReference(page) {
+		if it.fetcher == nil {
+			it.err = xerrors.Errorf("CollectionIterator: %q: %w", id, ErrNoFetcher)
+			return false
+		}
+
+		fetched, err := it.fetcher.Fetch(it.ctx, id)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		page = fetched
+		it.seen[id] = true
+	}
+
+	if err := it.load(page); err != nil {
+		it.err = err
+		return false
+	}
+	if len(it.items) == 0 {
+		return false
+	}
+
+	next, err := page.FetchObject("next")
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.next = next
+	return true
+}
+
+// load queues the inline items of a collection or page.
+func (it *CollectionIterator) load(page *Object) error {
+	if id := page.ID(); len(id) > 0 {
+		it.seen[id] = true
+	}
+
+	for _, key := range []string{"orderedItems", "items"} {
+		items, err := page.FetchList(key)
+		if err != nil {
+			return err
+		}
+		it.items = append(it.items, items...)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package apub_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestCollectionIterator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("inline items", func(t *testing.T) {
+		coll := Parse(t, `{
+			"id": "https://mastodon.gamedev.place/users/bob/collections/featured",
+			"type": "OrderedCollection",
+			"totalItems": 2,
+			"orderedItems": [
+				{"id": "https://mastodon.gamedev.place/users/bob/statuses/1", "type": "Note"},
+				"https://mastodon.gamedev.place/users/bob/statuses/2"
+			]
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, nil, coll)
+		assert.Equal(t, []string{
+			"https://mastodon.gamedev.place/users/bob/statuses/1",
+			"https://mastodon.gamedev.place/users/bob/statuses/2",
+		}, collect(t, iter))
+		total, ok := iter.TotalItems()
+		assert.True(t, ok)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		fetcher := fixtureFetcher(t, map[string]string{
+			"https://example.com/followers?page=1": `{
+				"id": "https://example.com/followers?page=1",
+				"type": "OrderedCollectionPage",
+				"partOf": "https://example.com/followers",
+				"next": "https://example.com/followers?page=2",
+				"orderedItems": ["https://example.com/a", "https://example.com/b"]
+			}`,
+			"https://example.com/followers?page=2": `{
+				"id": "https://example.com/followers?page=2",
+				"type": "OrderedCollectionPage",
+				"partOf": "https://example.com/followers",
+				"next": "https://example.com/followers?page=3",
+				"orderedItems": ["https://example.com/c"]
+			}`,
+			"https://example.com/followers?page=3": `{
+				"id": "https://example.com/followers?page=3",
+				"type": "OrderedCollectionPage",
+				"partOf": "https://example.com/followers",
+				"orderedItems": []
+			}`,
+		})
+
+		coll := Parse(t, `{
+			"id": "https://example.com/followers",
+			"type": "OrderedCollection",
+			"totalItems": "3",
+			"first": "https://example.com/followers?page=1"
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, fetcher, coll)
+		assert.Equal(t, []string{
+			"https://example.com/a",
+			"https://example.com/b",
+			"https://example.com/c",
+		}, collect(t, iter))
+		total, ok := iter.TotalItems()
+		assert.True(t, ok)
+		assert.Equal(t, 3, total)
+	})
+
+	t.Run("embedded first page", func(t *testing.T) {
+		fetcher := fixtureFetcher(t, map[string]string{
+			"https://example.com/outbox?page=2": `{
+				"id": "https://example.com/outbox?page=2",
+				"type": "CollectionPage",
+				"items": [{"id": "https://example.com/create/2", "type": "Create"}]
+			}`,
+		})
+
+		coll := Parse(t, `{
+			"id": "https://example.com/outbox",
+			"type": "Collection",
+			"first": {
+				"id": "https://example.com/outbox?page=1",
+				"type": "CollectionPage",
+				"next": "https://example.com/outbox?page=2",
+				"items": [{"id": "https://example.com/create/1", "type": "Create"}]
+			}
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, fetcher, coll)
+		assert.Equal(t, []string{
+			"https://example.com/create/1",
+			"https://example.com/create/2",
+		}, collect(t, iter))
+		_, ok := iter.TotalItems()
+		assert.False(t, ok)
+	})
+
+	t.Run("page", func(t *testing.T) {
+		fetcher := fixtureFetcher(t, map[string]string{
+			"https://example.com/outbox?page=2": `{
+				"id": "https://example.com/outbox?page=2",
+				"type": "OrderedCollectionPage",
+				"orderedItems": ["https://example.com/create/2"]
+			}`,
+		})
+
+		page := Parse(t, `{
+			"id": "https://example.com/outbox?page=1",
+			"type": "OrderedCollectionPage",
+			"first": "https://example.com/outbox?page=0",
+			"next": "https://example.com/outbox?page=2",
+			"orderedItems": ["https://example.com/create/1"]
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, fetcher, page)
+		assert.Equal(t, []string{
+			"https://example.com/create/1",
+			"https://example.com/create/2",
+		}, collect(t, iter))
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		fetcher := fixtureFetcher(t, map[string]string{
+			"https://example.com/following?page=1": `{
+				"id": "https://example.com/following?page=1",
+				"type": "OrderedCollectionPage",
+				"next": "https://example.com/following?page=2",
+				"orderedItems": ["https://example.com/a"]
+			}`,
+			"https://example.com/following?page=2": `{
+				"id": "https://example.com/following?page=2",
+				"type": "OrderedCollectionPage",
+				"next": "https://example.com/following?page=1",
+				"orderedItems": ["https://example.com/b"]
+			}`,
+		})
+
+		coll := Parse(t, `{
+			"id": "https://example.com/following",
+			"type": "OrderedCollection",
+			"first": "https://example.com/following?page=1"
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, fetcher, coll)
+		var ids []string
+		for iter.Next() {
+			ids = append(ids, iter.Item().ID())
+		}
+		assert.Equal(t, []string{"https://example.com/a", "https://example.com/b"}, ids)
+		assert.True(t, xerrors.Is(iter.Err(), apub.ErrCollectionLoop), iter.Err())
+	})
+
+	t.Run("inline loop", func(t *testing.T) {
+		coll := Parse(t, `{
+			"id": "https://example.com/following",
+			"type": "OrderedCollection",
+			"first": {
+				"id": "https://example.com/following?page=1",
+				"type": "OrderedCollectionPage",
+				"orderedItems": ["https://example.com/a"],
+				"next": {
+					"id": "https://example.com/following?page=1",
+					"type": "OrderedCollectionPage",
+					"orderedItems": ["https://example.com/b"]
+				}
+			}
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, nil, coll)
+		var ids []string
+		for iter.Next() {
+			ids = append(ids, iter.Item().ID())
+		}
+		assert.Equal(t, []string{"https://example.com/a"}, ids)
+		assert.True(t, xerrors.Is(iter.Err(), apub.ErrCollectionLoop), iter.Err())
+	})
+
+	t.Run("first is the collection", func(t *testing.T) {
+		coll := Parse(t, `{
+			"id": "https://example.com/following",
+			"type": "OrderedCollection",
+			"first": "https://example.com/following",
+			"orderedItems": ["https://example.com/a"]
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, nil, coll)
+		assert.Equal(t, []string{"https://example.com/a"}, collect(t, iter))
+	})
+
+	t.Run("no fetcher", func(t *testing.T) {
+		coll := Parse(t, `{
+			"type": "OrderedCollection",
+			"first": "https://example.com/following?page=1"
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, nil, coll)
+		assert.False(t, iter.Next())
+		assert.True(t, xerrors.Is(iter.Err(), apub.ErrNoFetcher), iter.Err())
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		coll := Parse(t, `{
+			"type": "OrderedCollection",
+			"first": "https://example.com/missing"
+		}`)
+
+		iter := apub.NewCollectionIterator(ctx, fixtureFetcher(t, nil), coll)
+		assert.False(t, iter.Next())
+		assert.True(t, xerrors.Is(iter.Err(), apub.ErrFetchFailed), iter.Err())
+	})
+}
+
+func collect(t *testing.T, iter *apub.CollectionIterator) []string {
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Item().ID())
+	}
+	require.Nil(t, iter.Err())
+	return ids
+}
+
+func fixtureFetcher(t *testing.T, fixtures map[string]string) apub.Fetcher {
+	return apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+		input, ok := fixtures[iri]
+		if !ok {
+			return nil, xerrors.Errorf("%q: %w", iri, apub.ErrFetchFailed)
+		}
+		return Parse(t, input), nil
+	})
+}
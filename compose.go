@@ -0,0 +1,291 @@
+package apub
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// MentionLookup resolves a mentioned account, such as
+// "@bob@mastodon.gamedev.place", to its actor.
+type MentionLookup interface {
+	LookupMention(ctx context.Context, acct Acct) (*Object, error)
+}
+
+// MentionLookupFunc adapts a function to a MentionLookup.
+type MentionLookupFunc func(ctx context.Context, acct Acct) (*Object, error)
+
+func (f MentionLookupFunc) LookupMention(ctx context.Context, acct Acct) (*Object, error) {
+	return f(ctx, acct)
+}
+
+// Composer builds a Note from plain text, with linked mentions, hashtags, and
+// URLs in its HTML content, and matching Mention, Hashtag, and Emoji tags.
+type Composer struct {
+	// Mentions resolves mentioned accounts. Mentions are left as text if nil,
+	// or if the lookup returns nil or ErrAcctNotFound.
+	Mentions MentionLookup
+
+	// Host is the domain of mentions without one, such as "@bob".
+	Host string
+
+	// HashtagURL returns the href of a hashtag, such as
+	// "https://example.com/tags/gamedev". Hashtags are tagged but not linked
+	// if nil.
+	HashtagURL func(tag string) string
+
+	// Emojis maps custom emoji shortcodes, without colons, to the URLs of
+	// their images.
+	Emojis map[string]string
+}
+
+// Compose returns a Note attributed to the author, and addressed to the
+// mentioned actors and the given visibility's audience. Blank lines in the
+// text separate paragraphs. VisibilityLimited and VisibilityUnknown are
+// rejected, since a Note addressed only to its mentions is direct.
+func (c *Composer) Compose(ctx context.Context, author *Object, text string, v VisibilityLevel) (*Object, error) {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate, VisibilityDirect:
+	default:
+		return nil, xerrors.Errorf("Compose: %s: %w", v, ErrInvalidVisibility)
+	}
+
+	b := &composition{tagged: make(map[string]bool)}
+
+	paragraphs := paragraphBreaks.Split(strings.TrimSpace(text), -1)
+	var content strings.Builder
+	for _, para := range paragraphs {
+		content.WriteString("<p>")
+		for i, line := range strings.Split(para, "\n") {
+			if i > 0 {
+				content.WriteString("<br>")
+			}
+			if err := c.linkify(ctx, &content, b, line); err != nil {
+				return nil, err
+			}
+		}
+		content.WriteString("</p>")
+	}
+
+	data := map[string]interface{}{
+		"@context":     "https://www.w3.org/ns/activitystreams",
+		"type":         "Note",
+		"attributedTo": author.ID(),
+		"content":      content.String(),
+	}
+	if len(b.tags) > 0 {
+		data["tag"] = b.tags
+	}
+
+	followers := author.Str("followers")
+	var to, cc []interface{}
+	switch v {
+	case VisibilityPublic:
+		to = []interface{}{Public}
+		cc = appendNonEmpty(cc, followers)
+	case VisibilityUnlisted:
+		to = appendNonEmpty(to, followers)
+		cc = []interface{}{Public}
+	case VisibilityPrivate:
+		to = appendNonEmpty(to, followers)
+	}
+	if len(to) == 0 {
+		to, b.mentioned = b.mentioned, nil
+	}
+	cc = append(cc, b.mentioned...)
+	if len(to) > 0 {
+		data["to"] = to
+	}
+	if len(cc) > 0 {
+		data["cc"] = cc
+	}
+	return New(data), nil
+}
+
+// composition collects the tags and mentioned actors of a Note.
+type composition struct {
+	tags      []interface{}
+	mentioned []interface{}
+	tagged    map[string]bool
+}
+
+func (b *composition) tag(key string, tag map[string]interface{}) {
+	if !b.tagged[key] {
+		b.tagged[key] = true
+		b.tags = append(b.tags, tag)
+	}
+}
+
+var (
+	paragraphBreaks = regexp.MustCompile(`\n\s*\n`)
+
+	// composeTokens matches URLs, mentions, hashtags, and emoji shortcodes.
+	composeTokens = regexp.MustCompile(`https?://[^\s<>"]+` +
+		`|@([\p{L}\p{N}_]+(?:[.-]+[\p{L}\p{N}_]+)*)(?:@([\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)+))?` +
+		`|#([\p{L}\p{N}_]+)` +
+		`|:([A-Za-z0-9_]+):`)
+)
+
+// linkify writes the escaped line, with links for its URLs, mentions, and
+// hashtags.
+func (c *Composer) linkify(ctx context.Context, w *strings.Builder, b *composition, line string) error {
+	last, shortcodeEnd := 0, -1
+	for _, m := range composeTokens.FindAllStringSubmatchIndex(line, -1) {
+		start, end := m[0], m[1]
+		// shortcodes can follow each other, like ":a::b:".
+		if !tokenBoundary(line, start) && !(m[8] >= 0 && start == shortcodeEnd) {
+			continue
+		}
+
+		var linked string
+		switch {
+		case m[2] >= 0:
+			acct := Acct{User: line[m[2]:m[3]], Host: c.Host}
+			if m[4] >= 0 {
+				acct.Host = strings.ToLower(line[m[4]:m[5]])
+			}
+			var err error
+			if linked, err = c.mention(ctx, b, acct); err != nil {
+				return err
+			}
+		case m[6] >= 0:
+			linked = c.hashtag(b, line[m[6]:m[7]])
+		case m[8] >= 0:
+			c.emoji(b, line[m[8]:m[9]])
+			shortcodeEnd = end
+		default:
+			end = start + len(trimURL(line[start:end]))
+			u := html.EscapeString(line[start:end])
+			linked = `<a href="` + u + `" rel="nofollow noopener noreferrer">` + u + `</a>`
+		}
+
+		if len(linked) == 0 {
+			continue
+		}
+		w.WriteString(html.EscapeString(line[last:start]))
+		w.WriteString(linked)
+		last = end
+	}
+	w.WriteString(html.EscapeString(line[last:]))
+	return nil
+}
+
+// mention looks up the account, and returns the link to its profile.
+func (c *Composer) mention(ctx context.Context, b *composition, acct Acct) (string, error) {
+	if c.Mentions == nil || len(acct.Host) == 0 {
+		return "", nil
+	}
+
+	actor, err := c.Mentions.LookupMention(ctx, acct)
+	if xerrors.Is(err, ErrAcctNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", xerrors.Errorf("Compose: %s: %w", acct, err)
+	}
+	if actor == nil || len(actor.ID()) == 0 {
+		return "", nil
+	}
+
+	id := actor.ID()
+	if !b.tagged[id] {
+		b.mentioned = append(b.mentioned, id)
+	}
+	b.tag(id, map[string]interface{}{
+		"type": "Mention",
+		"href": id,
+		"name": "@" + acct.User + "@" + acct.Host,
+	})
+
+	profile := id
+	for _, u := range actor.URLs() {
+		if href := u.DefaultValue(); len(href) > 0 {
+			profile = href
+			break
+		}
+	}
+	return `<span class="h-card"><a href="` + html.EscapeString(profile) +
+		`" class="u-url mention">@<span>` + html.EscapeString(acct.User) + `</span></a></span>`, nil
+}
+
+// hashtag tags the hashtag, and returns its link if the Composer has a
+// HashtagURL.
+func (c *Composer) hashtag(b *composition, name string) string {
+	if strings.IndexFunc(name, unicode.IsLetter) < 0 {
+		return ""
+	}
+
+	tag := map[string]interface{}{"type": "Hashtag", "name": "#" + name}
+	var href string
+	if c.HashtagURL != nil {
+		href = c.HashtagURL(normalizeHashtag(name))
+		tag["href"] = href
+	}
+	b.tag("#"+normalizeHashtag(name), tag)
+
+	if len(href) == 0 {
+		return ""
+	}
+	return `<a href="` + html.EscapeString(href) + `" class="mention hashtag" rel="tag">#<span>` +
+		html.EscapeString(name) + `</span></a>`
+}
+
+// emoji tags a known custom emoji. The shortcode is left in the content.
+func (c *Composer) emoji(b *composition, shortcode string) {
+	icon, ok := c.Emojis[shortcode]
+	if !ok {
+		return
+	}
+	b.tag(":"+shortcode+":", map[string]interface{}{
+		"type": "Emoji",
+		"name": ":" + shortcode + ":",
+		"icon": map[string]interface{}{
+			"type": "Image",
+			"url":  icon,
+		},
+	})
+}
+
+// tokenBoundary returns true if a token can start at i, which is not in the
+// middle of a word, like the "@" in an email address.
+func tokenBoundary(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return !(unicode.IsLetter(r) || unicode.IsNumber(r) || strings.ContainsRune("_/@#:", r))
+}
+
+// trimURL strips trailing punctuation from a URL. Closing brackets are only
+// stripped if they are unbalanced, like the ")" in "(see https://e.com/a)",
+// but not in "https://e.com/a_(b)".
+func trimURL(s string) string {
+	for len(s) > 0 {
+		c := s[len(s)-1]
+		switch c {
+		case '.', ',', ':', ';', '!', '?', '\'':
+		case ')', ']', '}':
+			if strings.Count(s, string(bracketPairs[c])) >= strings.Count(s, string(c)) {
+				return s
+			}
+		default:
+			return s
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+var bracketPairs = map[byte]byte{')': '(', ']': '[', '}': '{'}
+
+func appendNonEmpty(list []interface{}, s string) []interface{} {
+	if len(s) > 0 {
+		list = append(list, s)
+	}
+	return list
+}
@@ -0,0 +1,137 @@
+package apub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+)
+
+func TestComposer(t *testing.T) {
+	ctx := context.Background()
+	actors := map[string]string{
+		"acct:bob@mastodon.gamedev.place": `{
+			"id": "https://mastodon.gamedev.place/users/bob",
+			"type": "Person",
+			"url": "https://mastodon.gamedev.place/@bob"
+		}`,
+		"acct:erik@example.com": `{"id": "https://example.com/~erik", "type": "Person"}`,
+	}
+	composer := &apub.Composer{
+		Mentions: apub.MentionLookupFunc(func(ctx context.Context, acct apub.Acct) (*apub.Object, error) {
+			if acct.Host == "down.example" {
+				return nil, errors.New("timeout")
+			}
+			if js, ok := actors[acct.String()]; ok {
+				return Parse(t, js), nil
+			}
+			return nil, apub.ErrAcctNotFound
+		}),
+		Host: "example.com",
+		HashtagURL: func(tag string) string {
+			return "https://example.com/tags/" + tag
+		},
+		Emojis: map[string]string{
+			"blobcat": "https://example.com/emoji/blobcat.png",
+			"blobfox": "https://example.com/emoji/blobfox.png",
+		},
+	}
+	author := Parse(t, `{
+		"id": "https://example.com/~carol",
+		"type": "Person",
+		"followers": "https://example.com/~carol/followers"
+	}`)
+
+	t.Run("public", func(t *testing.T) {
+		note, err := composer.Compose(ctx, author, "Hello @bob@mastodon.gamedev.place #GameDev :blobcat:", apub.VisibilityPublic)
+		require.Nil(t, err)
+		assertJSON(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"type": "Note",
+			"attributedTo": "https://example.com/~carol",
+			"content": "<p>Hello <span class=\"h-card\"><a href=\"https://mastodon.gamedev.place/@bob\" class=\"u-url mention\">@<span>bob</span></a></span> <a href=\"https://example.com/tags/gamedev\" class=\"mention hashtag\" rel=\"tag\">#<span>GameDev</span></a> :blobcat:</p>",
+			"to": ["https://www.w3.org/ns/activitystreams#Public"],
+			"cc": ["https://example.com/~carol/followers", "https://mastodon.gamedev.place/users/bob"],
+			"tag": [
+				{"type": "Mention", "href": "https://mastodon.gamedev.place/users/bob", "name": "@bob@mastodon.gamedev.place"},
+				{"type": "Hashtag", "href": "https://example.com/tags/gamedev", "name": "#GameDev"},
+				{"type": "Emoji", "name": ":blobcat:", "icon": {"type": "Image", "url": "https://example.com/emoji/blobcat.png"}}
+			]
+		}`, note)
+
+		assert.Equal(t, apub.VisibilityPublic, apub.Visibility(note))
+		mentions, hashtags, emojis := note.LinkedTags("")
+		assert.Equal(t, 1, len(mentions))
+		assert.Equal(t, 1, len(hashtags))
+		assert.Equal(t, 1, len(emojis))
+		assert.Equal(t, "Hello @bob #GameDev :blobcat:", note.ContentText(""))
+		assert.Contains(t, note.ContentSafe(""), `class="u-url mention"`)
+	})
+
+	t.Run("visibility", func(t *testing.T) {
+		tests := []struct {
+			level  apub.VisibilityLevel
+			to, cc []string
+		}{
+			{
+				level: apub.VisibilityUnlisted,
+				to:    []string{"https://example.com/~carol/followers"},
+				cc:    []string{apub.Public, "https://example.com/~erik"},
+			},
+			{
+				level: apub.VisibilityPrivate,
+				to:    []string{"https://example.com/~carol/followers"},
+				cc:    []string{"https://example.com/~erik"},
+			},
+			{
+				level: apub.VisibilityDirect,
+				to:    []string{"https://example.com/~erik"},
+			},
+		}
+
+		for _, test := range tests {
+			note, err := composer.Compose(ctx, author, "hi @erik and @erik@example.com", test.level)
+			require.Nil(t, err)
+			assert.Equal(t, test.to, note.To(), test.level.String())
+			assert.Equal(t, test.cc, note.CC(), test.level.String())
+			assert.Equal(t, 1, len(note.Mentions()))
+			assert.Equal(t, test.level, apub.Visibility(note))
+		}
+	})
+
+	t.Run("invalid visibility", func(t *testing.T) {
+		for _, level := range []apub.VisibilityLevel{apub.VisibilityLimited, apub.VisibilityUnknown} {
+			_, err := composer.Compose(ctx, author, "hi @erik", level)
+			assert.True(t, errors.Is(err, apub.ErrInvalidVisibility), level.String())
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		note, err := composer.Compose(ctx, author, "first <b>line</b> & email me@example.com\nsee https://example.com/a?b=1&c=2.\n\n\nnot #1, @nobody@example.com, #go#lang or :unknown:", apub.VisibilityDirect)
+		require.Nil(t, err)
+		assert.Equal(t, `<p>first &lt;b&gt;line&lt;/b&gt; &amp; email me@example.com<br>`+
+			`see <a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">https://example.com/a?b=1&amp;c=2</a>.</p>`+
+			`<p>not #1, @nobody@example.com, <a href="https://example.com/tags/go" class="mention hashtag" rel="tag">#<span>go</span></a>#lang or :unknown:</p>`,
+			note.Str("content"))
+		assert.Equal(t, 1, len(note.Tags()))
+		assert.Nil(t, note.To())
+	})
+
+	t.Run("brackets and shortcodes", func(t *testing.T) {
+		note, err := composer.Compose(ctx, author, "https://e.com/a_(b) (https://e.com/c) :blobcat::blobfox:", apub.VisibilityDirect)
+		require.Nil(t, err)
+		assert.Equal(t, `<p><a href="https://e.com/a_(b)" rel="nofollow noopener noreferrer">https://e.com/a_(b)</a> `+
+			`(<a href="https://e.com/c" rel="nofollow noopener noreferrer">https://e.com/c</a>) :blobcat::blobfox:</p>`,
+			note.Str("content"))
+		_, _, emojis := note.LinkedTags("")
+		assert.Equal(t, 2, len(emojis))
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		_, err := composer.Compose(ctx, author, "hi @bob@down.example", apub.VisibilityPublic)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,381 @@
+package apub
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// activeContext is a processed JSON-LD @context, used to resolve the
+// properties of an object to their absolute IRIs.
+type activeContext struct {
+	terms    map[string]*termDefinition
+	sorted   []string
+	vocab    string
+	base     string
+	language string
+	loader   DocumentLoader
+}
+
+type termDefinition struct {
+	id        string
+	typ       string
+	container string
+	reverse   bool
+}
+
+func newActiveContext(loader DocumentLoader) *activeContext {
+	return &activeContext{
+		terms:  make(map[string]*termDefinition),
+		loader: loader,
+	}
+}
+
+func (c *activeContext) clone() *activeContext {
+	c2 := &activeContext{
+		terms:    make(map[string]*termDefinition, len(c.terms)),
+		vocab:    c.vocab,
+		base:     c.base,
+		language: c.language,
+		loader:   c.loader,
+	}
+	for k, v := range c.terms {
+		c2.terms[k] = v
+	}
+	return c2
+}
+
+// parse processes a local @context value and returns a new active context.
+// Contexts that fail to load are skipped, and the first error is returned
+// alongside the partially processed context.
+func (c *activeContext) parse(local interface{}) (*activeContext, error) {
+	return c.parseRemote(local, make(map[string]bool))
+}
+
+func (c *activeContext) parseRemote(local interface{}, remotes map[string]bool) (*activeContext, error) {
+	result := c.clone()
+	list, ok := local.([]interface{})
+	if !ok {
+		list = []interface{}{local}
+	}
+
+	var firstErr error
+	for _, item := range list {
+		var err error
+		switch ctx := item.(type) {
+		case nil:
+			result = newActiveContext(c.loader)
+			result.base = c.base
+		case string:
+			if remotes[ctx] {
+				err = xerrors.Errorf("Context: %q: recursive inclusion: %w", ctx, ErrInvalidContext)
+				break
+			}
+			var doc interface{}
+			doc, err = result.loadContext(ctx)
+			if err != nil {
+				break
+			}
+			remotes[ctx] = true
+			result, err = result.parseRemote(doc, remotes)
+			delete(remotes, ctx)
+		case map[string]interface{}:
+			err = result.define(ctx)
+		default:
+			err = xerrors.Errorf("Context: %T %+v: %w", item, item, ErrInvalidContext)
+		}
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	result.sorted = sortedTermKeys(result.terms)
+	return result, firstErr
+}
+
+func (c *activeContext) define(local map[string]interface{}) error {
+	if v, ok := local["@base"]; ok {
+		s, _ := v.(string)
+		c.base = s
+	}
+	if v, ok := local["@vocab"]; ok {
+		s, _ := v.(string)
+		if len(s) > 0 && !strings.Contains(s, ":") {
+			s, _ = c.expandIRI(s, true, true, nil, nil)
+		}
+		c.vocab = s
+	}
+	if v, ok := local["@language"]; ok {
+		s, _ := v.(string)
+		c.language = strings.ToLower(s)
+	}
+
+	keys := make([]string, 0, len(local))
+	for k := range local {
+		if !strings.HasPrefix(k, "@") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	defined := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if err := c.createTerm(local, k, defined); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *activeContext) createTerm(local map[string]interface{}, term string, defined map[string]bool) error {
+	if done, ok := defined[term]; ok {
+		if done {
+			return nil
+		}
+		return xerrors.Errorf("Context: term %q: cyclic IRI mapping: %w", term, ErrInvalidContext)
+	}
+	defined[term] = false
+	delete(c.terms, term)
+
+	def := &termDefinition{}
+	var id string
+	hasID := false
+
+	switch val := local[term].(type) {
+	case nil:
+		c.terms[term] = nil
+		defined[term] = true
+		return nil
+	case string:
+		id, hasID = val, true
+	case map[string]interface{}:
+		if rev, ok := val["@reverse"].(string); ok {
+			id, hasID, def.reverse = rev, true, true
+		} else if rawID, ok := val["@id"]; ok {
+			if rawID == nil {
+				c.terms[term] = nil
+				defined[term] = true
+				return nil
+			}
+			s, ok := rawID.(string)
+			if !ok {
+				return xerrors.Errorf("Context: term %q: @id %T %+v: %w", term, rawID, rawID, ErrInvalidContext)
+			}
+			id, hasID = s, true
+		}
+
+		if ty, ok := val["@type"].(string); ok {
+			expanded, err := c.expandIRI(ty, false, true, local, defined)
+			if err != nil {
+				return err
+			}
+			def.typ = expanded
+		}
+
+		switch ct := val["@container"].(type) {
+		case string:
+			def.container = ct
+		case []interface{}:
+			for _, item := range ct {
+				if s, ok := item.(string); ok && (len(def.container) == 0 || def.container == "@set") {
+					def.container = s
+				}
+			}
+		}
+	default:
+		return xerrors.Errorf("Context: term %q: %T %+v: %w", term, val, val, ErrInvalidContext)
+	}
+
+	switch {
+	case hasID && id != term:
+		if isKeyword(id) {
+			def.id = id
+			break
+		}
+		expanded, err := c.expandIRI(id, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		def.id = expanded
+	case strings.Contains(term, ":"):
+		expanded, err := c.expandIRI(term, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		def.id = expanded
+	case len(c.vocab) > 0:
+		def.id = c.vocab + term
+	default:
+		return xerrors.Errorf("Context: term %q: no IRI mapping: %w", term, ErrInvalidContext)
+	}
+
+	c.terms[term] = def
+	defined[term] = true
+	return nil
+}
+
+// expandTerm expands a term or compact IRI, such as "featured" or
+// "toot:featured", to its absolute IRI.
+func (c *activeContext) expandTerm(value string) string {
+	iri, _ := c.expandIRI(value, false, true, nil, nil)
+	return iri
+}
+
+func (c *activeContext) expandIRI(value string, relative, vocab bool, local map[string]interface{}, defined map[string]bool) (string, error) {
+	if len(value) == 0 || isKeyword(value) {
+		return value, nil
+	}
+
+	if local != nil {
+		if _, ok := local[value]; ok && !defined[value] {
+			if err := c.createTerm(local, value, defined); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if def, ok := c.terms[value]; ok && vocab {
+		if def == nil {
+			return "", nil
+		}
+		return def.id, nil
+	}
+
+	if i := strings.Index(value, ":"); i >= 0 {
+		prefix, suffix := value[:i], value[i+1:]
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value, nil
+		}
+		if local != nil {
+			if _, ok := local[prefix]; ok && !defined[prefix] {
+				if err := c.createTerm(local, prefix, defined); err != nil {
+					return "", err
+				}
+			}
+		}
+		if def := c.terms[prefix]; def != nil {
+			return def.id + suffix, nil
+		}
+		return value, nil
+	}
+
+	if vocab && len(c.vocab) > 0 {
+		return c.vocab + value, nil
+	}
+
+	if relative && len(c.base) > 0 {
+		base, err := url.Parse(c.base)
+		if err != nil {
+			return value, nil
+		}
+		ref, err := url.Parse(value)
+		if err != nil {
+			return value, nil
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	return value, nil
+}
+
+func isKeyword(s string) bool {
+	return strings.HasPrefix(s, "@") && jsonldKeywords[s]
+}
+
+var jsonldKeywords = map[string]bool{
+	"@base":      true,
+	"@container": true,
+	"@context":   true,
+	"@direction": true,
+	"@graph":     true,
+	"@id":        true,
+	"@import":    true,
+	"@included":  true,
+	"@index":     true,
+	"@json":      true,
+	"@language":  true,
+	"@list":      true,
+	"@nest":      true,
+	"@none":      true,
+	"@prefix":    true,
+	"@propagate": true,
+	"@protected": true,
+	"@reverse":   true,
+	"@set":       true,
+	"@type":      true,
+	"@value":     true,
+	"@version":   true,
+	"@vocab":     true,
+}
+
+// DocumentLoader loads the JSON-LD document for a remote @context IRI.
+type DocumentLoader interface {
+	LoadDocument(iri string) (interface{}, error)
+}
+
+// DocumentLoaderFunc adapts a function into a DocumentLoader.
+type DocumentLoaderFunc func(iri string) (interface{}, error)
+
+func (f DocumentLoaderFunc) LoadDocument(iri string) (interface{}, error) {
+	return f(iri)
+}
+
+// DefaultDocumentLoader is used when a Parser has no Loader. It never makes
+// network requests.
+var DefaultDocumentLoader DocumentLoader = &BuiltinLoader{}
+
+// BuiltinLoader loads the bundled ActivityStreams and security contexts.
+type BuiltinLoader struct {
+	mu    sync.Mutex
+	cache map[string]interface{}
+}
+
+func (l *BuiltinLoader) LoadDocument(iri string) (interface{}, error) {
+	if alias, ok := builtinContextAliases[iri]; ok {
+		iri = alias
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if doc, ok := l.cache[iri]; ok {
+		return doc, nil
+	}
+
+	raw, ok := builtinContexts[iri]
+	if !ok {
+		return nil, xerrors.Errorf("LoadDocument: %q: %w", iri, ErrContextNotFound)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, xerrors.Errorf("LoadDocument: %q: %v: %w", iri, err, ErrInvalidContext)
+	}
+
+	if l.cache == nil {
+		l.cache = make(map[string]interface{})
+	}
+	l.cache[iri] = doc
+	return doc, nil
+}
+
+func (c *activeContext) loadContext(iri string) (interface{}, error) {
+	loader := c.loader
+	if loader == nil {
+		loader = DefaultDocumentLoader
+	}
+
+	doc, err := loader.LoadDocument(iri)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, xerrors.Errorf("Context: %q: %T: %w", iri, doc, ErrInvalidContext)
+	}
+	return m["@context"], nil
+}
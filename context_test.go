@@ -0,0 +1,154 @@
+package apub_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestParseContext(t *testing.T) {
+	t.Run("mastodon person", func(t *testing.T) {
+		obj := ParseContext(t, `{
+			"@context": [
+				"https://www.w3.org/ns/activitystreams",
+				"https://w3id.org/security/v1",
+				{
+					"manuallyApprovesFollowers": "as:manuallyApprovesFollowers",
+					"toot": "http://joinmastodon.org/ns#",
+					"featured": {
+						"@id": "toot:featured",
+						"@type": "@id"
+					},
+					"schema": "http://schema.org#",
+					"PropertyValue": "schema:PropertyValue",
+					"value": "schema:value"
+				}
+			],
+			"id": "https://mastodon.gamedev.place/users/bob",
+			"type": "Person",
+			"featured": "https://mastodon.gamedev.place/users/bob/collections/featured",
+			"inbox": "https://mastodon.gamedev.place/users/bob/inbox",
+			"manuallyApprovesFollowers": true,
+			"publicKey": {
+				"id": "https://mastodon.gamedev.place/users/bob#main-key",
+				"owner": "https://mastodon.gamedev.place/users/bob",
+				"publicKeyPem": "-----BEGIN PUBLIC KEY-----\n-----END PUBLIC KEY-----\n"
+			},
+			"attachment": [{
+				"type": "PropertyValue",
+				"name": "Website",
+				"value": "https://example.com"
+			}]
+		}`)
+
+		featured := "https://mastodon.gamedev.place/users/bob/collections/featured"
+		assert.Equal(t, featured, obj.Str("featured"))
+		assert.Equal(t, featured, obj.Str("toot:featured"))
+		assert.Equal(t, featured, obj.Str("http://joinmastodon.org/ns#featured"))
+		assert.Equal(t, []string{featured}, obj.IDs("http://joinmastodon.org/ns#featured"))
+
+		assert.True(t, obj.Bool("manuallyApprovesFollowers"))
+		assert.True(t, obj.Bool("as:manuallyApprovesFollowers"))
+		assert.True(t, obj.Bool("https://www.w3.org/ns/activitystreams#manuallyApprovesFollowers"))
+
+		assert.Equal(t, "Person", obj.Str("@type"))
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob", obj.Str("@id"))
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob/inbox", obj.Str("http://www.w3.org/ns/ldp#inbox"))
+
+		key := obj.Object("https://w3id.org/security#publicKey")
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob", key.Str("sec:owner"))
+		assert.True(t, strings.HasPrefix(key.Str("https://w3id.org/security#publicKeyPem"), "-----BEGIN"))
+
+		atts := obj.List("as:attachment")
+		if assert.Equal(t, 1, len(atts)) {
+			assert.Equal(t, "https://example.com", atts[0].Str("http://schema.org#value"))
+		}
+
+		assert.Equal(t, "", obj.Str("toot:missing"))
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("language map", func(t *testing.T) {
+		obj := ParseContext(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"type": "Note",
+			"contentMap": {
+				"en": "<p>EN Content</p>"
+			}
+		}`)
+
+		assert.Equal(t, "<p>EN Content</p>", obj.Content(""))
+		assert.Equal(t, "<p>EN Content</p>", obj.Object("as:content").Str("en"))
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("nested context", func(t *testing.T) {
+		obj := ParseContext(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"type": "Create",
+			"object": {
+				"@context": {"toot": "http://joinmastodon.org/ns#"},
+				"type": "Note",
+				"toot:blurhash": "abc"
+			}
+		}`)
+
+		note := obj.Object("as:object")
+		assert.Equal(t, "Note", note.Type())
+		assert.Equal(t, "abc", note.Str("http://joinmastodon.org/ns#blurhash"))
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("mutations", func(t *testing.T) {
+		obj := ParseContext(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"type": "Note",
+			"summary": "cw"
+		}`)
+
+		require.Nil(t, obj.SetStr("name", "title"))
+		assert.Equal(t, "title", obj.Str("as:name"))
+
+		obj.Del("as:summary")
+		assert.Equal(t, "", obj.Str("summary"))
+		assert.Equal(t, "title", obj.Str("as:name"))
+	})
+
+	t.Run("without context processing", func(t *testing.T) {
+		obj := Parse(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"type": "Note",
+			"attributedTo": "https://example.com/~erik"
+		}`)
+		assert.Equal(t, "", obj.Str("as:attributedTo"))
+		assert.Equal(t, "https://example.com/~erik", obj.Str("attributedTo"))
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		obj := ParseContext(t, `{
+			"@context": [
+				"https://example.com/unknown-context",
+				"https://www.w3.org/ns/activitystreams"
+			],
+			"type": "Note",
+			"attributedTo": "https://example.com/~erik"
+		}`)
+		assert.Equal(t, "https://example.com/~erik", obj.Str("as:attributedTo"))
+
+		errs := obj.Errors()
+		if assert.Equal(t, 1, len(errs), errs) {
+			assert.True(t, xerrors.Is(errs[0], apub.ErrContextNotFound), errs[0])
+		}
+	})
+}
+
+func ParseContext(t *testing.T, input string) *apub.Object {
+	dec := &apub.Parser{ProcessContext: true}
+	obj, err := dec.Parse(strings.NewReader(input))
+	require.Nil(t, err)
+	return obj
+}
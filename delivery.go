@@ -0,0 +1,412 @@
+package apub
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultMaxAttempts is used by a Deliverer without MaxAttempts.
+	DefaultMaxAttempts = 8
+
+	// DefaultBackoff is used by a Deliverer without a Backoff.
+	DefaultBackoff = time.Minute
+
+	// DefaultMaxBackoff is used by a Deliverer without a MaxBackoff.
+	DefaultMaxBackoff = 24 * time.Hour
+
+	// DefaultDeliveryTimeout is used by a Deliverer without a Timeout.
+	DefaultDeliveryTimeout = 30 * time.Second
+)
+
+// Delivery is a queued POST of an activity to an inbox.
+type Delivery struct {
+	ID       string
+	Inbox    string
+	Activity []byte
+
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+}
+
+// DeliveryQueue stores pending deliveries, so that they survive restarts. Its
+// methods are called concurrently for deliveries to different hosts.
+type DeliveryQueue interface {
+	// Enqueue adds a delivery, or replaces the one with the same ID.
+	Enqueue(ctx context.Context, d *Delivery) error
+
+	// Due returns the deliveries with a NextAttempt at or before now.
+	Due(ctx context.Context, now time.Time) ([]*Delivery, error)
+
+	// Remove deletes a delivery that has succeeded.
+	Remove(ctx context.Context, id string) error
+
+	// DeadLetter removes a delivery that has failed for good, and keeps it
+	// for inspection.
+	DeadLetter(ctx context.Context, d *Delivery) error
+}
+
+// Deliverer delivers activities to the inboxes of their recipients.
+type Deliverer struct {
+	// Fetcher resolves recipients to actors.
+	Fetcher Fetcher
+
+	// Expander expands collections in the recipients, such as followers, to
+	// their members. If nil, collections are delivered to only if they have
+	// an inbox.
+	Expander *RecipientExpander
+
+	// Client posts activities. It should sign them, such as with an
+	// httpsig.Transport. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	Queue DeliveryQueue
+
+	// MaxAttempts is the number of attempts before a delivery is dead
+	// lettered. DefaultMaxAttempts is used if zero.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, which doubles after each
+	// attempt. DefaultBackoff is used if zero.
+	Backoff time.Duration
+
+	// MaxBackoff is the longest delay between retries. DefaultMaxBackoff is
+	// used if zero.
+	MaxBackoff time.Duration
+
+	// Timeout limits each attempt, so that an inbox that hangs doesn't hold
+	// up others. DefaultDeliveryTimeout is used if zero.
+	Timeout time.Duration
+
+	// Now returns the current time. time.Now is used if nil.
+	Now func() time.Time
+}
+
+// Deliver queues the activity for each inbox of its recipients, without its
+// bto and bcc properties. The activity must have an id, which identifies its
+// deliveries in the queue. Recipients that can't be resolved are skipped, and
+// the first error is returned.
+func (d *Deliverer) Deliver(ctx context.Context, act *Object) error {
+	if len(act.ID()) == 0 {
+		return xerrors.Errorf("Deliver: %s: %w", act.Type(), ErrMissingID)
+	}
+
+	inboxes, resolveErr := d.Inboxes(ctx, act)
+
+	body, err := withoutHiddenRecipients(act).MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	for _, inbox := range inboxes {
+		err := d.Queue.Enqueue(ctx, &Delivery{
+			ID:          act.ID() + " " + inbox,
+			Inbox:       inbox,
+			Activity:    body,
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return resolveErr
+}
+
+// Inboxes resolves the activity's recipients to inboxes, skipping the Public
+// collection and the activity's actor. Recipients on the same host share one
+// delivery if any of them has a sharedInbox endpoint. If they list different
+// sharedInbox endpoints, the first in sorted order is used.
+func (d *Deliverer) Inboxes(ctx context.Context, act *Object) ([]string, error) {
+	var firstErr error
+	var recipients []string
+	if d.Expander != nil {
+		recipients, firstErr = d.Expander.Expand(ctx, act)
+	} else {
+		recipients = Recipients(act)
+	}
+
+	sender := act.Str("actor")
+	hosts := make(map[string]*hostInboxes)
+	for _, id := range recipients {
+		if IsPublic(id) || id == sender {
+			continue
+		}
+
+		actor, err := d.Fetcher.Fetch(ctx, id)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = xerrors.Errorf("Inboxes: %q: %w", id, err)
+			}
+			continue
+		}
+
+		inbox := actor.Str("inbox")
+		if len(inbox) == 0 {
+			continue
+		}
+
+		u, err := url.Parse(inbox)
+		if err != nil {
+			continue
+		}
+
+		h := hosts[u.Host]
+		if h == nil {
+			h = &hostInboxes{inboxes: make(map[string]bool)}
+			hosts[u.Host] = h
+		}
+		h.inboxes[inbox] = true
+		shared := actor.Object("endpoints").Str("sharedInbox")
+		if len(shared) > 0 && (len(h.shared) == 0 || shared < h.shared) {
+			h.shared = shared
+		}
+	}
+
+	var inboxes []string
+	for _, h := range hosts {
+		if len(h.shared) > 0 {
+			inboxes = append(inboxes, h.shared)
+			continue
+		}
+		for inbox := range h.inboxes {
+			inboxes = append(inboxes, inbox)
+		}
+	}
+	sort.Strings(inboxes)
+	return inboxes, firstErr
+}
+
+type hostInboxes struct {
+	shared  string
+	inboxes map[string]bool
+}
+
+// Process attempts every due delivery. Each host's deliveries are attempted in
+// order, concurrently with other hosts. Failed deliveries are queued again
+// with exponential backoff, or dead lettered after MaxAttempts, or right away
+// if the inbox rejects them with a 4xx status.
+func (d *Deliverer) Process(ctx context.Context) error {
+	due, err := d.Queue.Due(ctx, d.now())
+	if err != nil {
+		return err
+	}
+
+	var hosts []string
+	byHost := make(map[string][]*Delivery)
+	for _, delivery := range due {
+		host := delivery.Inbox
+		if u, err := url.Parse(delivery.Inbox); err == nil {
+			host = u.Host
+		}
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], delivery)
+	}
+
+	errs := make([]error, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, deliveries []*Delivery) {
+			defer wg.Done()
+			for _, delivery := range deliveries {
+				if err := d.attempt(ctx, delivery); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+		}(i, byHost[host])
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run processes due deliveries at the given interval, until the context is
+// done.
+func (d *Deliverer) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Process(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Deliverer) attempt(ctx context.Context, delivery *Delivery) error {
+	delivery.Attempts++
+	retry, err := d.post(ctx, delivery)
+	if err == nil {
+		return d.Queue.Remove(ctx, delivery.ID)
+	}
+
+	delivery.LastError = err.Error()
+	if !retry || delivery.Attempts >= d.maxAttempts() {
+		return d.Queue.DeadLetter(ctx, delivery)
+	}
+
+	delivery.NextAttempt = d.now().Add(d.backoff(delivery.Attempts))
+	return d.Queue.Enqueue(ctx, delivery)
+}
+
+// backoff returns the delay after the given number of attempts, doubling
+// from Backoff up to MaxBackoff.
+func (d *Deliverer) backoff(attempts int) time.Duration {
+	backoff := d.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	max := d.MaxBackoff
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
+
+// post sends the delivery, and returns whether a failure can be retried.
+func (d *Deliverer) post(ctx context.Context, delivery *Delivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.Inbox, bytes.NewReader(delivery.Activity))
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout())
+	defer cancel()
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/activity+json")
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestTimeout:
+		return true, xerrors.Errorf("deliver to %q: %s: %w", delivery.Inbox, res.Status, ErrDeliveryFailed)
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		return false, xerrors.Errorf("deliver to %q: %s: %w", delivery.Inbox, res.Status, ErrDeliveryRejected)
+	default:
+		return true, xerrors.Errorf("deliver to %q: %s: %w", delivery.Inbox, res.Status, ErrDeliveryFailed)
+	}
+}
+
+func (d *Deliverer) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return DefaultDeliveryTimeout
+}
+
+func (d *Deliverer) maxAttempts() int {
+	if d.MaxAttempts > 0 {
+		return d.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+func (d *Deliverer) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+// MemoryQueue is a DeliveryQueue that does not persist deliveries.
+type MemoryQueue struct {
+	mu          sync.Mutex
+	deliveries  map[string]*Delivery
+	deadLetters []*Delivery
+}
+
+func (q *MemoryQueue) Enqueue(ctx context.Context, d *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.deliveries == nil {
+		q.deliveries = make(map[string]*Delivery)
+	}
+	copied := *d
+	q.deliveries[d.ID] = &copied
+	return nil
+}
+
+func (q *MemoryQueue) Due(ctx context.Context, now time.Time) ([]*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*Delivery
+	for _, d := range q.deliveries {
+		if !d.NextAttempt.After(now) {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	return due, nil
+}
+
+func (q *MemoryQueue) Remove(ctx context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.deliveries, id)
+	return nil
+}
+
+func (q *MemoryQueue) DeadLetter(ctx context.Context, d *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.deliveries, d.ID)
+	copied := *d
+	q.deadLetters = append(q.deadLetters, &copied)
+	return nil
+}
+
+// Pending returns the number of queued deliveries.
+func (q *MemoryQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.deliveries)
+}
+
+// DeadLetters returns the deliveries that have failed for good.
+func (q *MemoryQueue) DeadLetters() []*Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]*Delivery(nil), q.deadLetters...)
+}
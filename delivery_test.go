@@ -0,0 +1,288 @@
+package apub_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestDeliverer(t *testing.T) {
+	var mu sync.Mutex
+	posts := make(map[string][]string)
+	statuses := map[string][]int{
+		"/carol/inbox": {http.StatusServiceUnavailable, http.StatusAccepted},
+		"/dave/inbox":  {http.StatusForbidden},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "application/activity+json", r.Header.Get("Content-Type"))
+		posts[r.URL.Path] = append(posts[r.URL.Path], string(body))
+
+		status := http.StatusAccepted
+		if s := statuses[r.URL.Path]; len(s) > 0 {
+			status = s[0]
+			statuses[r.URL.Path] = s[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	shared := httptest.NewServer(server.Config.Handler)
+	defer shared.Close()
+
+	actors := map[string]string{
+		"https://a.example/alice": `{"type": "Person", "inbox": "` + shared.URL + `/alice/inbox", "endpoints": {"sharedInbox": "` + shared.URL + `/inbox"}}`,
+		"https://a.example/bob":   `{"type": "Person", "inbox": "` + shared.URL + `/bob/inbox"}`,
+		"https://b.example/carol": `{"type": "Person", "inbox": "` + server.URL + `/carol/inbox"}`,
+		"https://b.example/dave":  `{"type": "Person", "inbox": "` + server.URL + `/dave/inbox"}`,
+		"https://b.example/group": `{"type": "Collection", "items": []}`,
+	}
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	queue := &apub.MemoryQueue{}
+	deliverer := &apub.Deliverer{
+		Fetcher: apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			if js, ok := actors[iri]; ok {
+				return Parse(t, js), nil
+			}
+			return nil, errors.New("not found")
+		}),
+		Queue:       queue,
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+		Now:         func() time.Time { return now },
+	}
+
+	act := Parse(t, `{
+		"id": "https://example.com/create/1",
+		"type": "Create",
+		"actor": "https://example.com/~erik",
+		"to": ["https://www.w3.org/ns/activitystreams#Public", "https://a.example/alice", "https://b.example/group"],
+		"cc": ["https://a.example/bob", "https://b.example/carol"],
+		"bcc": ["https://b.example/dave", "https://c.example/missing"],
+		"object": {"type": "Note", "attributedTo": "https://example.com/~erik"}
+	}`)
+
+	inboxes, err := deliverer.Inboxes(context.Background(), act)
+	assert.True(t, strings.Contains(err.Error(), `"https://c.example/missing": not found`), err)
+	assert.ElementsMatch(t, []string{
+		server.URL + "/carol/inbox",
+		server.URL + "/dave/inbox",
+		shared.URL + "/inbox",
+	}, inboxes)
+
+	err = deliverer.Deliver(context.Background(), act)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, queue.Pending())
+
+	require.Nil(t, deliverer.Process(context.Background()))
+	assert.Equal(t, 1, len(posts["/inbox"]))
+	assert.Equal(t, 1, len(posts["/carol/inbox"]))
+	assert.Equal(t, 1, len(posts["/dave/inbox"]))
+	assert.False(t, strings.Contains(posts["/inbox"][0], "bcc"), posts["/inbox"][0])
+	assert.True(t, strings.Contains(posts["/inbox"][0], `"id":"https://example.com/create/1"`), posts["/inbox"][0])
+
+	assert.Equal(t, 1, queue.Pending())
+	dead := queue.DeadLetters()
+	if assert.Equal(t, 1, len(dead)) {
+		assert.Equal(t, server.URL+"/dave/inbox", dead[0].Inbox)
+		assert.Equal(t, 1, dead[0].Attempts)
+		assert.True(t, strings.Contains(dead[0].LastError, "403"), dead[0].LastError)
+	}
+
+	// carol's retry isn't due yet
+	require.Nil(t, deliverer.Process(context.Background()))
+	assert.Equal(t, 1, len(posts["/carol/inbox"]))
+
+	now = now.Add(time.Minute)
+	require.Nil(t, deliverer.Process(context.Background()))
+	assert.Equal(t, 2, len(posts["/carol/inbox"]))
+	assert.Equal(t, 0, queue.Pending())
+	assert.Equal(t, 1, len(queue.DeadLetters()))
+}
+
+func TestDelivererRetries(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	queue := &apub.MemoryQueue{}
+	deliverer := &apub.Deliverer{
+		Fetcher: apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			return Parse(t, `{"type": "Person", "inbox": "`+server.URL+`/inbox"}`), nil
+		}),
+		Queue:       queue,
+		MaxAttempts: 3,
+		Now:         func() time.Time { return now },
+	}
+
+	act := Parse(t, `{"id": "https://example.com/like/1", "type": "Like", "to": "https://b.example/bob"}`)
+	require.Nil(t, deliverer.Deliver(context.Background(), act))
+
+	for _, wait := range []time.Duration{0, time.Minute, 2 * time.Minute} {
+		now = now.Add(wait - time.Second)
+		require.Nil(t, deliverer.Process(context.Background()))
+		now = now.Add(time.Second)
+		require.Nil(t, deliverer.Process(context.Background()))
+	}
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 0, queue.Pending())
+	dead := queue.DeadLetters()
+	if assert.Equal(t, 1, len(dead)) {
+		assert.Equal(t, 3, dead[0].Attempts)
+		assert.True(t, strings.Contains(dead[0].LastError, "500"), dead[0].LastError)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := deliverer.Run(ctx, time.Hour)
+	assert.True(t, xerrors.Is(err, context.Canceled), err)
+}
+
+func TestDelivererMaxBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	queue := &delayQueue{MemoryQueue: &apub.MemoryQueue{}, now: &now}
+	deliverer := &apub.Deliverer{
+		Fetcher: apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			return Parse(t, `{"type": "Person", "inbox": "`+server.URL+`/inbox"}`), nil
+		}),
+		Queue:       queue,
+		MaxAttempts: 80,
+		Backoff:     time.Hour,
+		MaxBackoff:  4 * time.Hour,
+		Now:         func() time.Time { return now },
+	}
+
+	act := Parse(t, `{"id": "https://example.com/like/1", "type": "Like", "to": "https://b.example/bob"}`)
+	require.Nil(t, deliverer.Deliver(context.Background(), act))
+	for i := 0; i < 79; i++ {
+		require.Nil(t, deliverer.Process(context.Background()))
+		require.Equal(t, i+2, len(queue.delays))
+		now = now.Add(queue.delays[i+1])
+	}
+	require.Nil(t, deliverer.Process(context.Background()))
+
+	assert.Equal(t, []time.Duration{0, time.Hour, 2 * time.Hour, 4 * time.Hour, 4 * time.Hour}, queue.delays[:5])
+	assert.Equal(t, 4*time.Hour, queue.delays[len(queue.delays)-1])
+	if dead := queue.DeadLetters(); assert.Equal(t, 1, len(dead)) {
+		assert.Equal(t, 80, dead[0].Attempts)
+	}
+}
+
+func TestDelivererMissingID(t *testing.T) {
+	queue := &apub.MemoryQueue{}
+	deliverer := &apub.Deliverer{
+		Fetcher: apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			return Parse(t, `{"type": "Person", "inbox": "https://b.example/bob/inbox"}`), nil
+		}),
+		Queue: queue,
+	}
+
+	for _, js := range []string{
+		`{"type": "Like", "to": "https://b.example/bob", "object": "https://example.com/1"}`,
+		`{"type": "Like", "to": "https://b.example/bob", "object": "https://example.com/2"}`,
+	} {
+		err := deliverer.Deliver(context.Background(), Parse(t, js))
+		assert.True(t, xerrors.Is(err, apub.ErrMissingID), err)
+	}
+	assert.Equal(t, 0, queue.Pending())
+}
+
+// delayQueue records the delay of each enqueued delivery.
+type delayQueue struct {
+	*apub.MemoryQueue
+	now    *time.Time
+	delays []time.Duration
+}
+
+func (q *delayQueue) Enqueue(ctx context.Context, d *apub.Delivery) error {
+	q.delays = append(q.delays, d.NextAttempt.Sub(*q.now))
+	return q.MemoryQueue.Enqueue(ctx, d)
+}
+
+func TestDelivererTimeout(t *testing.T) {
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer hung.Close()
+	defer close(release)
+
+	var mu sync.Mutex
+	var posts int
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ok.Close()
+
+	actors := map[string]string{
+		"https://a.example/alice": `{"type": "Person", "inbox": "` + hung.URL + `/alice/inbox"}`,
+		"https://b.example/bob":   `{"type": "Person", "inbox": "` + ok.URL + `/bob/inbox"}`,
+	}
+	queue := &apub.MemoryQueue{}
+	deliverer := &apub.Deliverer{
+		Fetcher: apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			return Parse(t, actors[iri]), nil
+		}),
+		Queue:   queue,
+		Timeout: 50 * time.Millisecond,
+	}
+
+	act := Parse(t, `{"id": "https://example.com/like/1", "type": "Like", "to": ["https://a.example/alice", "https://b.example/bob"]}`)
+	require.Nil(t, deliverer.Deliver(context.Background(), act))
+	require.Nil(t, deliverer.Process(context.Background()))
+
+	assert.Equal(t, 1, posts)
+	assert.Equal(t, 1, queue.Pending())
+	assert.Equal(t, 0, len(queue.DeadLetters()))
+}
+
+func TestDelivererSharedInboxes(t *testing.T) {
+	actors := map[string]string{
+		"https://a.example/alice": `{"type": "Person", "inbox": "https://a.example/alice/inbox", "endpoints": {"sharedInbox": "https://a.example/shared/2"}}`,
+		"https://a.example/bob":   `{"type": "Person", "inbox": "https://a.example/bob/inbox", "endpoints": {"sharedInbox": "https://a.example/shared/1"}}`,
+	}
+	deliverer := &apub.Deliverer{
+		Fetcher: apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			return Parse(t, actors[iri]), nil
+		}),
+	}
+
+	for _, to := range []string{
+		`["https://a.example/alice", "https://a.example/bob"]`,
+		`["https://a.example/bob", "https://a.example/alice"]`,
+	} {
+		act := Parse(t, `{"id": "https://example.com/like/1", "type": "Like", "to": `+to+`}`)
+		inboxes, err := deliverer.Inboxes(context.Background(), act)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"https://a.example/shared/1"}, inboxes)
+	}
+}
@@ -0,0 +1,62 @@
+package apub
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := o.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (o *Object) UnmarshalJSON(b []byte) error {
+	var data map[string]interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	lang := o.lang
+	o.reset(data)
+	if len(lang) > 0 {
+		o.lang = lang
+	}
+	return nil
+}
+
+// WriteTo encodes the object as JSON to the given writer. Keys are written in
+// sorted order, and HTML characters in values are left unescaped so that
+// encoded objects can be compared with their parsed source. A nil object is
+// written as null.
+func (o *Object) WriteTo(w io.Writer) (int64, error) {
+	var data interface{} = map[string]interface{}{}
+	if o == nil {
+		data = nil
+	} else if o.data != nil {
+		data = o.data
+	}
+
+	cw := &countWriter{w: w}
+	enc := json.NewEncoder(cw)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(data)
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
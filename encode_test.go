@@ -0,0 +1,94 @@
+package apub_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+)
+
+func TestEncode(t *testing.T) {
+	obj := Parse(t, `{
+		"type": "Note",
+		"id": "http://example.com/note/1",
+		"content": "A simple <em>note</em>",
+		"published": "2015-02-10T15:04:55Z",
+		"to": ["https://example.org/~john/"],
+		"attributedTo": {
+			"type": "Person",
+			"id": "http://example.com/~erik"
+		}
+	}`)
+
+	t.Run("WriteTo", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := obj.WriteTo(&buf)
+		require.Nil(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+		assert.Equal(t, `{"attributedTo":{"id":"http://example.com/~erik","type":"Person"},`+
+			`"content":"A simple <em>note</em>","id":"http://example.com/note/1",`+
+			`"published":"2015-02-10T15:04:55Z","to":["https://example.org/~john/"],"type":"Note"}`+"\n",
+			buf.String())
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		b1, err := json.Marshal(obj)
+		require.Nil(t, err)
+
+		obj2 := Parse(t, string(b1))
+		b2, err := json.Marshal(obj2)
+		require.Nil(t, err)
+		assert.Equal(t, string(b1), string(b2))
+		assert.Equal(t, "Note", obj2.Type())
+		assert.Equal(t, "http://example.com/~erik", obj2.Str("attributedTo"))
+	})
+
+	t.Run("mutations", func(t *testing.T) {
+		obj := Parse(t, `{"type": "Note", "inner": {"a": 1}}`)
+		obj.Object("inner").SetStr("b", "2")
+		obj.SetStr("content", "hi")
+
+		b, err := json.Marshal(obj)
+		require.Nil(t, err)
+		assert.Equal(t, `{"content":"hi","inner":{"a":1,"b":"2"},"type":"Note"}`, string(b))
+	})
+
+	t.Run("activity", func(t *testing.T) {
+		act := apub.CreateActivity(obj)
+		b, err := json.Marshal(act)
+		require.Nil(t, err)
+		assert.Contains(t, string(b), `"type":"Create"`)
+		assert.Contains(t, string(b), `"object":{"attributedTo":`)
+	})
+
+	t.Run("UnmarshalJSON", func(t *testing.T) {
+		var obj apub.Object
+		require.Nil(t, json.Unmarshal([]byte(`{"type": "Note", "content": "hi", "cc": "http://example.com/cc"}`), &obj))
+		assert.Equal(t, "Note", obj.Type())
+		assert.Equal(t, "hi", obj.Content(""))
+		assert.Equal(t, []string{"http://example.com/cc"}, obj.CC())
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("null", func(t *testing.T) {
+		var obj apub.Object
+		require.Nil(t, json.Unmarshal([]byte(`null`), &obj))
+		obj.SetStr("type", "Note")
+		assert.Equal(t, "Note", obj.Type())
+
+		var nilObj *apub.Object
+		var buf bytes.Buffer
+		_, err := nilObj.WriteTo(&buf)
+		require.Nil(t, err)
+		assert.Equal(t, "null\n", buf.String())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		b, err := json.Marshal(obj.Object("missing"))
+		require.Nil(t, err)
+		assert.Equal(t, `{}`, string(b))
+	})
+}
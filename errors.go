@@ -7,15 +7,40 @@ import (
 )
 
 var (
-	ErrLangNotFound     = errors.New("key not translated to given language")
-	ErrLangMapNotFound  = errors.New("key has no language map")
-	ErrKeyTypeNotObject = errors.New("unable to decode type as object")
-	ErrInvalidBool      = errors.New("unable to decode value as bool")
-	ErrInvalidFloat     = errors.New("unable to decode value as float")
-	ErrInvalidIDs       = errors.New("unable to decode value as string IDs")
-	ErrInvalidInt       = errors.New("unable to decode value as int")
-	ErrInvalidTime      = errors.New("unable to decode value as time")
-	ErrInvalidList      = errors.New("unable to decode value as list")
+	ErrLangNotFound      = errors.New("key not translated to given language")
+	ErrLangMapNotFound   = errors.New("key has no language map")
+	ErrKeyTypeNotObject  = errors.New("unable to decode type as object")
+	ErrInvalidBool       = errors.New("unable to decode value as bool")
+	ErrInvalidFloat      = errors.New("unable to decode value as float")
+	ErrInvalidIDs        = errors.New("unable to decode value as string IDs")
+	ErrInvalidInt        = errors.New("unable to decode value as int")
+	ErrInvalidTime       = errors.New("unable to decode value as time")
+	ErrInvalidList       = errors.New("unable to decode value as list")
+	ErrFunctionalList    = errors.New("functional property has multiple values")
+	ErrContextNotFound   = errors.New("unable to load JSON-LD context")
+	ErrInvalidContext    = errors.New("invalid JSON-LD context")
+	ErrMissingID         = errors.New("object has no id")
+	ErrMissingType       = errors.New("object has no type")
+	ErrMissingActor      = errors.New("activity has no actor")
+	ErrInvalidIRI        = errors.New("value is not an absolute IRI")
+	ErrUnknownType       = errors.New("type is not in the vocabulary")
+	ErrInvalidKey        = errors.New("unable to decode public key")
+	ErrKeyOwnerMismatch  = errors.New("public key owner does not match actor")
+	ErrKeyNotFound       = errors.New("public key not found")
+	ErrDeliveryFailed    = errors.New("delivery failed")
+	ErrDeliveryRejected  = errors.New("delivery rejected by inbox")
+	ErrNoFetcher         = errors.New("object has no fetcher to resolve IRIs")
+	ErrFetchFailed       = errors.New("unable to fetch object")
+	ErrObjectTooLarge    = errors.New("fetched object is too large")
+	ErrMaxDepth          = errors.New("too many nested fetches")
+	ErrInvalidAcct       = errors.New("invalid acct URI")
+	ErrAcctNotFound      = errors.New("account not found")
+	ErrNoActorLink       = errors.New("no ActivityPub actor link")
+	ErrInvalidNodeInfo   = errors.New("invalid NodeInfo document")
+	ErrCollectionLoop    = errors.New("collection pages loop")
+	ErrOriginMismatch    = errors.New("ids are not from the same origin")
+	ErrSignerMismatch    = errors.New("activity is not signed by its actor")
+	ErrInvalidVisibility = errors.New("visibility level cannot be composed")
 )
 
 func FatalLangErr(err error) bool {
@@ -0,0 +1,15 @@
+package apub
+
+import "context"
+
+// Fetcher loads objects, such as remote actors, by id.
+type Fetcher interface {
+	Fetch(ctx context.Context, iri string) (*Object, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher.
+type FetcherFunc func(ctx context.Context, iri string) (*Object, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, iri string) (*Object, error) {
+	return f(ctx, iri)
+}
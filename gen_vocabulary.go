@@ -0,0 +1,279 @@
+//go:build ignore
+// +build ignore
+
+// gen_vocabulary.go generates the vocabulary tables and typed object views from
+// vocabulary.json. Run it with "go generate".
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+type vocabulary struct {
+	Types      []*vocabType     `json:"types"`
+	Properties []*vocabProperty `json:"properties"`
+
+	types map[string]*vocabType
+}
+
+type vocabType struct {
+	Name       string   `json:"name"`
+	Extends    []string `json:"extends"`
+	DefaultKey string   `json:"defaultKey"`
+	Extension  bool     `json:"extension"`
+
+	Methods []*vocabProperty
+}
+
+type vocabProperty struct {
+	Name       string   `json:"name"`
+	Domain     []string `json:"domain"`
+	IRIType    string   `json:"iriType"`
+	Functional bool     `json:"functional"`
+	Kind       string   `json:"kind"`
+	Method     string   `json:"method"`
+	Builtin    bool     `json:"builtin"`
+	IRI        bool     `json:"iri"`
+}
+
+var kinds = map[string][2]string{
+	"bool":   {"bool", "Bool"},
+	"float":  {"float64", "Float"},
+	"ids":    {"[]string", "IDs"},
+	"int":    {"int", "Int"},
+	"list":   {"[]*Object", "List"},
+	"object": {"*Object", "Object"},
+	"str":    {"string", "Str"},
+	"time":   {"time.Time", "Time"},
+}
+
+func (p *vocabProperty) MethodName() string {
+	if len(p.Method) > 0 {
+		return p.Method
+	}
+	return strings.ToUpper(p.Name[:1]) + p.Name[1:]
+}
+
+func (p *vocabProperty) ReturnType() string {
+	return kinds[p.Kind][0]
+}
+
+func (p *vocabProperty) Accessor() string {
+	return kinds[p.Kind][1]
+}
+
+func (p *vocabProperty) inDomain(ty string) bool {
+	for _, d := range p.Domain {
+		if d == ty {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *vocabType) Parent() string {
+	if len(t.Extends) == 0 {
+		return "Object"
+	}
+	return t.Extends[0]
+}
+
+func (t *vocabType) Embed() string {
+	if t.Parent() == "Object" {
+		return "*Object"
+	}
+	return "*" + t.Parent()
+}
+
+func (v *vocabulary) ancestors(ty string, seen map[string]bool) {
+	if seen[ty] {
+		return
+	}
+	seen[ty] = true
+	if t, ok := v.types[ty]; ok {
+		for _, ext := range t.Extends {
+			v.ancestors(ext, seen)
+		}
+	}
+}
+
+// Literal returns the expression that wraps an *Object named o in the view.
+func (v *vocabulary) Literal(ty string) string {
+	if ty == "Object" {
+		return "o"
+	}
+	return fmt.Sprintf("&%s{%s}", ty, v.Literal(v.types[ty].Parent()))
+}
+
+// methods returns the properties that need accessors on the given view. The
+// view embeds its first parent, so only properties of the type itself, and of
+// any other parents outside of that chain, are included.
+func (v *vocabulary) methods(t *vocabType) []*vocabProperty {
+	chain := map[string]bool{"Object": true}
+	for ty := t.Parent(); ty != "Object"; ty = v.types[ty].Parent() {
+		chain[ty] = true
+	}
+
+	own := map[string]bool{t.Name: true}
+	for i, ext := range t.Extends {
+		if i > 0 {
+			v.ancestors(ext, own)
+		}
+	}
+
+	var props []*vocabProperty
+	for _, p := range v.Properties {
+		if p.Builtin || len(p.Kind) == 0 || p.inDomain("Object") {
+			continue
+		}
+		for ty := range own {
+			if !chain[ty] && p.inDomain(ty) {
+				props = append(props, p)
+				break
+			}
+		}
+	}
+	return props
+}
+
+func main() {
+	raw, err := ioutil.ReadFile("vocabulary.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	v := &vocabulary{types: make(map[string]*vocabType)}
+	if err := json.Unmarshal(raw, v); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, t := range v.Types {
+		v.types[t.Name] = t
+	}
+	for _, p := range v.Properties {
+		if _, ok := kinds[p.Kind]; !ok && !p.Builtin {
+			log.Fatalf("property %q has unknown kind %q", p.Name, p.Kind)
+		}
+		for _, d := range p.Domain {
+			if _, ok := v.types[d]; !ok {
+				log.Fatalf("property %q has unknown domain %q", p.Name, d)
+			}
+		}
+	}
+
+	var views []*vocabType
+	for _, t := range v.Types {
+		if t.Name == "Object" {
+			continue
+		}
+		t.Methods = v.methods(t)
+		views = append(views, t)
+	}
+
+	var objectMethods []*vocabProperty
+	for _, p := range v.Properties {
+		if !p.Builtin && p.inDomain("Object") {
+			objectMethods = append(objectMethods, p)
+		}
+	}
+
+	sortedTypes := append([]*vocabType(nil), v.Types...)
+	sort.Slice(sortedTypes, func(i, j int) bool { return sortedTypes[i].Name < sortedTypes[j].Name })
+	sortedProps := append([]*vocabProperty(nil), v.Properties...)
+	sort.Slice(sortedProps, func(i, j int) bool { return sortedProps[i].Name < sortedProps[j].Name })
+
+	write("vocabulary_gen.go", tablesTemplate, map[string]interface{}{
+		"Types":      sortedTypes,
+		"Properties": sortedProps,
+	})
+	write("types_gen.go", viewsTemplate, map[string]interface{}{
+		"Vocab":         v,
+		"Views":         views,
+		"ObjectMethods": objectMethods,
+	})
+}
+
+func write(filename string, tmpl *template.Template, data interface{}) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("%s: %v\n%s", filename, err, buf.Bytes())
+	}
+
+	if err := ioutil.WriteFile(filename, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var funcs = template.FuncMap{
+	"quote": func(list []string) string {
+		quoted := make([]string, len(list))
+		for i, s := range list {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		return strings.Join(quoted, ", ")
+	},
+}
+
+var tablesTemplate = template.Must(template.New("tables").Funcs(funcs).Parse(`// Code generated by gen_vocabulary.go; DO NOT EDIT.
+
+package apub
+
+var vocabTypes = map[string]vocabType{
+{{- range .Types }}
+	{{ printf "%q" .Name }}: { {{- if .Extends }}extends: []string{ {{- quote .Extends -}} },{{ end }}{{ if .DefaultKey }} defaultKey: {{ printf "%q" .DefaultKey }},{{ end -}} },
+{{- end }}
+}
+
+var vocabProperties = map[string]vocabProperty{
+{{- range .Properties }}
+	{{ printf "%q" .Name }}: {domain: []string{ {{- quote .Domain -}} },{{ if .IRIType }} iriType: {{ printf "%q" .IRIType }},{{ end }}{{ if .Functional }} functional: true,{{ end }}{{ if .IRI }} iri: true,{{ end }}{{ if eq .Kind "time" }} dateTime: true,{{ end -}} },
+{{- end }}
+}
+`))
+
+var viewsTemplate = template.Must(template.New("views").Funcs(funcs).Parse(`// Code generated by gen_vocabulary.go; DO NOT EDIT.
+
+package apub
+
+import "time"
+{{ range .ObjectMethods }}
+// {{ .MethodName }} returns the {{ .Name }} property.
+func (o *Object) {{ .MethodName }}() {{ .ReturnType }} {
+	return o.{{ .Accessor }}({{ printf "%q" .Name }})
+}
+{{ end }}
+{{- range $t := .Views }}
+// {{ .Name }} is a typed view of {{ if .Extension }}the {{ .Name }} extension type{{ else }}an ActivityStreams {{ .Name }}{{ end }}.
+type {{ .Name }} struct {
+	{{ .Embed }}
+}
+
+// As{{ .Name }} returns a view of the object if its type is {{ .Name }}, or a
+// type that extends it.
+func As{{ .Name }}(o *Object) (*{{ .Name }}, bool) {
+	if o == nil || !o.IsType({{ printf "%q" .Name }}) {
+		return nil, false
+	}
+	return {{ $.Vocab.Literal .Name }}, true
+}
+{{ range .Methods }}
+// {{ .MethodName }} returns the {{ .Name }} property.
+func (v *{{ $t.Name }}) {{ .MethodName }}() {{ .ReturnType }} {
+	return v.Object.{{ .Accessor }}({{ printf "%q" .Name }})
+}
+{{ end }}
+{{- end }}
+`))
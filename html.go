@@ -0,0 +1,215 @@
+package apub
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLPolicy is an allowlist of HTML elements, attributes, and link schemes
+// for sanitizing content from other servers. Elements that are not allowed are
+// replaced with their children, except for elements like script and style
+// which are dropped along with their content.
+type HTMLPolicy struct {
+	// Elements maps each allowed element to its allowed attributes.
+	Elements map[string][]string
+
+	// Classes are the allowed values in class attributes, such as "mention"
+	// and "hashtag". Other classes are removed.
+	Classes []string
+
+	// URLSchemes are the allowed schemes of href attributes. Other links, and
+	// relative links, are removed.
+	URLSchemes []string
+
+	// Rel replaces the rel attribute of links, such as
+	// "nofollow noopener noreferrer". Rel attributes are removed if empty.
+	Rel string
+}
+
+// DefaultHTMLPolicy allows the same HTML as Mastodon.
+var DefaultHTMLPolicy = &HTMLPolicy{
+	Elements: map[string][]string{
+		"a":          {"href", "rel", "class", "translate"},
+		"b":          nil,
+		"blockquote": nil,
+		"br":         nil,
+		"code":       nil,
+		"del":        nil,
+		"em":         nil,
+		"i":          nil,
+		"li":         {"value"},
+		"ol":         {"start", "reversed"},
+		"p":          nil,
+		"pre":        nil,
+		"span":       {"class", "translate"},
+		"strong":     nil,
+		"u":          nil,
+		"ul":         nil,
+	},
+	Classes: []string{
+		"ellipsis", "h-card", "hashtag", "invisible", "mention", "u-url",
+	},
+	URLSchemes: []string{
+		"http", "https", "dat", "dweb", "ipfs", "ipns", "ssb", "gopher",
+		"xmpp", "magnet", "gemini",
+	},
+	Rel: "nofollow noopener noreferrer",
+}
+
+// ContentSafe returns the content property, sanitized with the
+// DefaultHTMLPolicy.
+func (o *Object) ContentSafe(lang string) string {
+	return DefaultHTMLPolicy.Sanitize(o.Content(lang))
+}
+
+// SummarySafe returns the summary property, sanitized with the
+// DefaultHTMLPolicy.
+func (o *Object) SummarySafe(lang string) string {
+	return DefaultHTMLPolicy.Sanitize(o.Summary(lang))
+}
+
+// NameSafe returns the name property, sanitized with the DefaultHTMLPolicy.
+func (o *Object) NameSafe(lang string) string {
+	return DefaultHTMLPolicy.Sanitize(o.Name(lang))
+}
+
+// Sanitize returns the HTML fragment with only the elements and attributes
+// that the policy allows.
+func (p *HTMLPolicy) Sanitize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+
+	nodes, err := parseHTMLFragment(s)
+	if err != nil {
+		return html.EscapeString(s)
+	}
+
+	out := &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
+	for _, n := range nodes {
+		p.sanitize(out, n)
+	}
+
+	var buf bytes.Buffer
+	for c := out.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return html.EscapeString(s)
+		}
+	}
+	return buf.String()
+}
+
+// sanitize appends a sanitized copy of the node to the parent.
+func (p *HTMLPolicy) sanitize(parent, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		parent.AppendChild(&html.Node{Type: html.TextNode, Data: n.Data})
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if droppedElements[n.DataAtom] {
+		return
+	}
+
+	allowed, ok := p.Elements[n.Data]
+	if !ok {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			p.sanitize(parent, c)
+		}
+		return
+	}
+
+	el := &html.Node{Type: html.ElementNode, DataAtom: n.DataAtom, Data: n.Data}
+	for _, attr := range n.Attr {
+		if len(attr.Namespace) > 0 || !containsString(allowed, attr.Key) {
+			continue
+		}
+		switch attr.Key {
+		case "class":
+			if attr.Val = p.classes(attr.Val); len(attr.Val) == 0 {
+				continue
+			}
+		case "href":
+			if !p.allowURL(attr.Val) {
+				continue
+			}
+		case "rel":
+			continue
+		}
+		el.Attr = append(el.Attr, attr)
+	}
+	if n.DataAtom == atom.A && len(p.Rel) > 0 && hasAttr(el, "href") {
+		el.Attr = append(el.Attr, html.Attribute{Key: "rel", Val: p.Rel})
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.sanitize(el, c)
+	}
+	parent.AppendChild(el)
+}
+
+// classes returns the allowed classes in a class attribute.
+func (p *HTMLPolicy) classes(val string) string {
+	var kept []string
+	for _, class := range strings.Fields(val) {
+		if containsString(p.Classes, class) {
+			kept = append(kept, class)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// allowURL returns true for absolute URLs with an allowed scheme.
+func (p *HTMLPolicy) allowURL(val string) bool {
+	u, err := url.Parse(strings.TrimSpace(val))
+	if err != nil || !u.IsAbs() {
+		return false
+	}
+	return containsString(p.URLSchemes, strings.ToLower(u.Scheme))
+}
+
+// parseHTMLFragment parses HTML as the children of a div.
+func parseHTMLFragment(s string) ([]*html.Node, error) {
+	div := &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
+	return html.ParseFragment(strings.NewReader(s), div)
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// droppedElements are removed with their content, instead of being replaced
+// with it.
+var droppedElements = map[atom.Atom]bool{
+	atom.Iframe:   true,
+	atom.Math:     true,
+	atom.Noscript: true,
+	atom.Object:   true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Svg:      true,
+	atom.Template: true,
+	atom.Textarea: true,
+	atom.Title:    true,
+}
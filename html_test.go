@@ -0,0 +1,98 @@
+package apub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/technoweenie/apub"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "allowed",
+			input:    `<p>A simple <em>note</em></p><p>with<br>a <code>break</code></p>`,
+			expected: `<p>A simple <em>note</em></p><p>with<br/>a <code>break</code></p>`,
+		},
+		{
+			name:     "mention",
+			input:    `<p><span class="h-card"><a href="https://mastodon.gamedev.place/@bob" class="u-url mention" target="_blank" rel="me">@<span>bob</span></a></span> hi</p>`,
+			expected: `<p><span class="h-card"><a href="https://mastodon.gamedev.place/@bob" class="u-url mention" rel="nofollow noopener noreferrer">@<span>bob</span></a></span> hi</p>`,
+		},
+		{
+			name:     "hashtag",
+			input:    `<a href="https://example.com/tags/go" class="mention hashtag status-link" rel="tag">#<span>go</span></a>`,
+			expected: `<a href="https://example.com/tags/go" class="mention hashtag" rel="nofollow noopener noreferrer">#<span>go</span></a>`,
+		},
+		{
+			name:     "invalid schemes",
+			input:    `<a href="javascript:alert(1)">one</a> <a href=" JaVaScRiPt:alert(1)">two</a> <a href="/relative">three</a> <a href="GEMINI://example.com">four</a>`,
+			expected: `<a>one</a> <a>two</a> <a>three</a> <a href="GEMINI://example.com" rel="nofollow noopener noreferrer">four</a>`,
+		},
+		{
+			name:     "unwrapped elements",
+			input:    `<div><h1 style="color: red">Title</h1><img src="https://example.com/a.png" onerror="alert(1)"><span onclick="alert(1)" class="custom">text</span></div>`,
+			expected: `Title<span>text</span>`,
+		},
+		{
+			name:     "dropped elements",
+			input:    `<p>before<script>alert("hi")</script><style>p { color: red }</style>after</p><iframe src="https://example.com"></iframe>`,
+			expected: `<p>beforeafter</p>`,
+		},
+		{
+			name:     "lists",
+			input:    `<ol start="3" reversed type="a"><li value="3">three</li></ol><ul><li>one</li></ul><blockquote><pre>quote</pre></blockquote>`,
+			expected: `<ol start="3" reversed=""><li value="3">three</li></ol><ul><li>one</li></ul><blockquote><pre>quote</pre></blockquote>`,
+		},
+		{
+			name:     "text",
+			input:    `1 < 2 & "quotes"`,
+			expected: `1 &lt; 2 &amp; &#34;quotes&#34;`,
+		},
+		{
+			name:     "unclosed",
+			input:    `<p><strong>bold`,
+			expected: `<p><strong>bold</strong></p>`,
+		},
+		{
+			name:     "comments",
+			input:    `a<!-- <script>alert(1)</script> -->b`,
+			expected: `ab`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, apub.DefaultHTMLPolicy.Sanitize(test.input))
+		})
+	}
+
+	t.Run("custom policy", func(t *testing.T) {
+		policy := &apub.HTMLPolicy{
+			Elements:   map[string][]string{"a": {"href"}, "p": nil},
+			URLSchemes: []string{"https"},
+		}
+		assert.Equal(t, `<p><a href="https://example.com">link</a> <a>gopher</a> bold</p>`,
+			policy.Sanitize(`<p><a href="https://example.com" class="mention">link</a> <a href="gopher://example.com">gopher</a> <b>bold</b></p>`))
+	})
+
+	t.Run("accessors", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Note",
+			"name": "<b>Title</b><script>x</script>",
+			"summary": "<p onclick=\"alert(1)\">CW</p>",
+			"content": "A simple <em>note</em><img src=\"x\">",
+			"contentMap": {
+				"es": "Una <em>nota</em> <a href=\"data:text/html,hi\">sencilla</a>"
+			}
+		}`)
+		assert.Equal(t, "<b>Title</b>", obj.NameSafe(""))
+		assert.Equal(t, "<p>CW</p>", obj.SummarySafe(""))
+		assert.Equal(t, "A simple <em>note</em>", obj.ContentSafe(""))
+		assert.Equal(t, "Una <em>nota</em> <a>sencilla</a>", obj.ContentSafe("es"))
+	})
+}
@@ -0,0 +1,15 @@
+package httpsig
+
+import "errors"
+
+var (
+	ErrNoSignature          = errors.New("request is not signed")
+	ErrMalformedSignature   = errors.New("unable to parse signature header")
+	ErrInvalidSignature     = errors.New("signature does not match")
+	ErrMissingHeader        = errors.New("signature does not cover required header")
+	ErrDigestMismatch       = errors.New("body does not match digest")
+	ErrClockSkew            = errors.New("request date is outside of allowed clock skew")
+	ErrSignatureExpired     = errors.New("signature has expired")
+	ErrUnsupportedKey       = errors.New("unsupported key type")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signature algorithm")
+)
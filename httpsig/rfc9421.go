@@ -0,0 +1,278 @@
+package httpsig
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultComponents are signed if an RFC 9421 Signer has no Headers.
+// Content-Digest is also signed for requests with a body.
+var DefaultComponents = []string{"@method", "@target-uri"}
+
+const signatureLabel = "sig1"
+
+func (s *Signer) signRFC9421(r *http.Request, body []byte) error {
+	components := s.Headers
+	if len(components) == 0 {
+		components = DefaultComponents
+		if body != nil {
+			components = append(components[:len(components):len(components)], "content-digest")
+		}
+	}
+
+	names := make([]string, len(components))
+	quoted := make([]string, len(components))
+	for i, c := range components {
+		names[i] = strings.ToLower(c)
+		quoted[i] = sfString(names[i])
+		if names[i] == "content-digest" && len(r.Header.Get("Content-Digest")) == 0 {
+			r.Header.Set("Content-Digest", contentDigest(body))
+		}
+	}
+
+	algorithm := algorithmRSAV15
+	if _, ok := s.PrivateKey.(ed25519.PrivateKey); ok {
+		algorithm = algorithmEd25519
+	}
+
+	now := s.now()
+	params := fmt.Sprintf("(%s);created=%d", strings.Join(quoted, " "), now.Unix())
+	if s.Expires > 0 {
+		params += fmt.Sprintf(";expires=%d", now.Add(s.Expires).Unix())
+	}
+	params += fmt.Sprintf(";keyid=%s;alg=%s", sfString(s.KeyID), sfString(algorithm))
+
+	base, err := signatureBase(r, names, params)
+	if err != nil {
+		return err
+	}
+
+	sig, err := sign(s.PrivateKey, []byte(base))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Signature-Input", signatureLabel+"="+params)
+	r.Header.Set("Signature", signatureLabel+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	return nil
+}
+
+// verifyRFC9421 verifies the first signature in the Signature-Input header.
+func (v *Verifier) verifyRFC9421(r *http.Request, body []byte) (string, error) {
+	inputs, err := parseDictionary(r.Header.Get("Signature-Input"))
+	if err != nil {
+		return "", err
+	}
+	sigs, err := parseDictionary(r.Header.Get("Signature"))
+	if err != nil {
+		return "", err
+	}
+
+	var input, signature *sfMember
+	for _, in := range inputs {
+		for _, s := range sigs {
+			if s.key == in.key {
+				input, signature = in, s
+				break
+			}
+		}
+		if input != nil {
+			break
+		}
+	}
+	if input == nil || input.items == nil {
+		return "", xerrors.Errorf("httpsig: no signature for Signature-Input: %w", ErrMalformedSignature)
+	}
+
+	keyID := input.params["keyid"]
+	sig, err := base64.StdEncoding.DecodeString(signature.value)
+	if err != nil || len(keyID) == 0 || len(sig) == 0 {
+		return keyID, xerrors.Errorf("httpsig: missing keyid or signature: %w", ErrMalformedSignature)
+	}
+
+	required := v.RequiredHeaders
+	if len(required) == 0 {
+		required = DefaultComponents
+	}
+	if body != nil {
+		required = append(required[:len(required):len(required)], "content-digest")
+	}
+	if err := checkCovered(input.items, required); err != nil {
+		return keyID, err
+	}
+
+	if err := v.checkTimestamps(r, input.items, input.params); err != nil {
+		return keyID, err
+	}
+	if err := checkContentDigest(r, body); err != nil {
+		return keyID, err
+	}
+
+	base, err := signatureBase(r, input.items, input.raw)
+	if err != nil {
+		return keyID, err
+	}
+
+	key, err := v.Keys.GetKey(r.Context(), keyID)
+	if err != nil {
+		return keyID, xerrors.Errorf("httpsig: key %q: %w", keyID, err)
+	}
+
+	if err := verify(key, input.params["alg"], []byte(base), sig); err != nil {
+		return keyID, xerrors.Errorf("httpsig: key %q: %w", keyID, err)
+	}
+	return keyID, nil
+}
+
+// checkTimestamps checks the created and expires signature parameters. If the
+// signature has no created time, it must cover a Date header instead, so that
+// it can't be replayed forever.
+func (v *Verifier) checkTimestamps(r *http.Request, components []string, params map[string]string) error {
+	now := v.now()
+	if created, ok := params["created"]; ok {
+		sec, err := strconv.ParseInt(created, 10, 64)
+		if err != nil {
+			return xerrors.Errorf("httpsig: created %q: %w", created, ErrMalformedSignature)
+		}
+		if d := now.Sub(time.Unix(sec, 0)); d > v.maxSkew() || d < -v.maxSkew() {
+			return xerrors.Errorf("httpsig: created %q: %w", created, ErrClockSkew)
+		}
+	} else {
+		if err := checkCovered(components, []string{"date"}); err != nil {
+			return xerrors.Errorf("httpsig: no created parameter: %w", err)
+		}
+		if len(r.Header.Get("Date")) == 0 {
+			return xerrors.Errorf("httpsig: no created parameter or Date header: %w", ErrMissingHeader)
+		}
+		if err := v.checkDate(r); err != nil {
+			return err
+		}
+	}
+
+	if expires, ok := params["expires"]; ok {
+		sec, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return xerrors.Errorf("httpsig: expires %q: %w", expires, ErrMalformedSignature)
+		}
+		if now.After(time.Unix(sec, 0)) {
+			return xerrors.Errorf("httpsig: expires %q: %w", expires, ErrSignatureExpired)
+		}
+	}
+	return nil
+}
+
+// signatureBase builds the signature base from the given components, ending
+// with the serialized signature parameters.
+func signatureBase(r *http.Request, components []string, params string) (string, error) {
+	var b strings.Builder
+	for _, c := range components {
+		value, err := componentValue(r, c)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s: %s\n", sfString(c), value)
+	}
+	fmt.Fprintf(&b, "%s: %s", sfString("@signature-params"), params)
+	return b.String(), nil
+}
+
+func componentValue(r *http.Request, c string) (string, error) {
+	switch c {
+	case "@method":
+		return r.Method, nil
+	case "@target-uri":
+		return targetScheme(r) + "://" + requestHost(r) + r.URL.RequestURI(), nil
+	case "@authority":
+		return strings.ToLower(requestHost(r)), nil
+	case "@scheme":
+		return targetScheme(r), nil
+	case "@request-target":
+		return r.URL.RequestURI(), nil
+	case "@path":
+		if p := r.URL.EscapedPath(); len(p) > 0 {
+			return p, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + r.URL.RawQuery, nil
+	}
+
+	if strings.HasPrefix(c, "@") {
+		return "", xerrors.Errorf("httpsig: component %q is not supported: %w", c, ErrMalformedSignature)
+	}
+
+	values := r.Header.Values(c)
+	if len(values) == 0 {
+		return "", xerrors.Errorf("httpsig: %q: %w", c, ErrMissingHeader)
+	}
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// targetScheme returns the scheme of the request. Incoming requests only have
+// a path, so the scheme is guessed from the connection, or the
+// X-Forwarded-Proto header set by a proxy.
+func targetScheme(r *http.Request) string {
+	if len(r.URL.Scheme) > 0 {
+		return r.URL.Scheme
+	}
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		return "https"
+	}
+	return "http"
+}
+
+// checkContentDigest compares the body against the Content-Digest header,
+// which is required for requests with a body.
+func checkContentDigest(r *http.Request, body []byte) error {
+	header := r.Header.Get("Content-Digest")
+	if len(header) == 0 {
+		if body == nil {
+			return nil
+		}
+		return xerrors.Errorf("httpsig: %q: %w", "content-digest", ErrMissingHeader)
+	}
+
+	digests, err := parseDictionary(header)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range digests {
+		var sum []byte
+		switch d.key {
+		case "sha-256":
+			s := sha256.Sum256(body)
+			sum = s[:]
+		case "sha-512":
+			s := sha512.Sum512(body)
+			sum = s[:]
+		default:
+			continue
+		}
+
+		expected, err := base64.StdEncoding.DecodeString(d.value)
+		if err != nil || subtle.ConstantTimeCompare(expected, sum) != 1 {
+			return xerrors.Errorf("httpsig: %q: %w", header, ErrDigestMismatch)
+		}
+		return nil
+	}
+	return xerrors.Errorf("httpsig: %q: %w", header, ErrUnsupportedAlgorithm)
+}
+
+func contentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
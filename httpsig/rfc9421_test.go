@@ -0,0 +1,227 @@
+package httpsig_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub/httpsig"
+	"golang.org/x/xerrors"
+)
+
+// rfcEd25519Key is the test-key-ed25519 public key from RFC 9421, appendix
+// B.1.4.
+const rfcEd25519Key = `-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEAJrQLj5P/89iXES9+vFgrIy29clF9CC/oPPsw3c5D0bs=
+-----END PUBLIC KEY-----`
+
+func TestRFC9421(t *testing.T) {
+	keys := map[string]crypto.PublicKey{
+		"rsa":     &testRSAKey.PublicKey,
+		"ed25519": testEd25519.Public(),
+	}
+	verifier := &httpsig.Verifier{
+		Keys: httpsig.KeyGetterFunc(func(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+			return keys[keyID], nil
+		}),
+		MaxSkew: time.Minute,
+		Now:     func() time.Time { return testTime.Add(30 * time.Second) },
+	}
+
+	sign := func(t *testing.T, signer *httpsig.Signer, method, body string) *http.Request {
+		req := newRequest(t, method, "https://mastodon.example/users/bob/inbox?page=1", body)
+		if signer.PrivateKey == nil {
+			signer.PrivateKey = testRSAKey
+		}
+		signer.Scheme = httpsig.RFC9421
+		signer.Now = func() time.Time { return testTime }
+		require.Nil(t, signer.Sign(req))
+		return req
+	}
+
+	t.Run("rfc test vector", func(t *testing.T) {
+		block, _ := pem.Decode([]byte(rfcEd25519Key))
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		require.Nil(t, err)
+		keys["test-key-ed25519"] = pub.(ed25519.PublicKey)
+
+		// the vector doesn't sign Content-Digest, so it only verifies without
+		// a body
+		req := httptest.NewRequest("POST", "/foo?param=Value&Pet=dog", nil)
+		req.Host = "example.com"
+		req.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Length", "18")
+		req.Header.Set("Signature-Input", `sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`)
+		req.Header.Set("Signature", "sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:")
+
+		v := *verifier
+		v.RequiredHeaders = []string{"@method", "@path", "@authority"}
+		v.Now = func() time.Time { return time.Unix(1618884473, 0) }
+		keyID, err := v.Verify(req)
+		require.Nil(t, err)
+		assert.Equal(t, "test-key-ed25519", keyID)
+
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"hello": "world"}`))
+		req.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+		_, err = v.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+	})
+
+	t.Run("sign", func(t *testing.T) {
+		req := sign(t, &httpsig.Signer{KeyID: "rsa"}, "POST", testActivity)
+		assert.Equal(t, `sig1=("@method" "@target-uri" "content-digest");created=1588334400;keyid="rsa";alg="rsa-v1_5-sha256"`,
+			req.Header.Get("Signature-Input"))
+		assert.Equal(t, "sha-256=:YtPfmjmLAyqv5NeoDgXRA1TFtv0GNzQhVLupP/UNyqQ=:", req.Header.Get("Content-Digest"))
+		assert.True(t, strings.HasPrefix(req.Header.Get("Signature"), "sig1=:"))
+		assert.Equal(t, "", req.Header.Get("Digest"))
+
+		keyID, err := verifier.Verify(req)
+		require.Nil(t, err)
+		assert.Equal(t, "rsa", keyID)
+	})
+
+	t.Run("ed25519 with expiration", func(t *testing.T) {
+		signer := &httpsig.Signer{
+			KeyID:      `ed"25519`,
+			PrivateKey: testEd25519,
+			Headers:    []string{"@method", "@authority", "@path", "@query", "Date"},
+			Expires:    time.Minute,
+		}
+		keys[`ed"25519`] = keys["ed25519"]
+		req := sign(t, signer, "GET", "")
+		assert.Equal(t, `sig1=("@method" "@authority" "@path" "@query" "date");created=1588334400;expires=1588334460;keyid="ed\"25519";alg="ed25519"`,
+			req.Header.Get("Signature-Input"))
+		assert.Equal(t, []string{"@method", "@authority", "@path", "@query", "Date"}, signer.Headers)
+
+		v := *verifier
+		v.RequiredHeaders = []string{"@method", "@path"}
+		keyID, err := v.Verify(req)
+		require.Nil(t, err)
+		assert.Equal(t, `ed"25519`, keyID)
+
+		v.Now = func() time.Time { return testTime.Add(2 * time.Minute) }
+		v.MaxSkew = time.Hour
+		_, err = v.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrSignatureExpired), err)
+	})
+
+	t.Run("incoming request", func(t *testing.T) {
+		req := sign(t, &httpsig.Signer{KeyID: "rsa"}, "POST", testActivity)
+		in := httptest.NewRequest("POST", "https://mastodon.example/users/bob/inbox?page=1", req.Body)
+		in.Header = req.Header
+		_, err := verifier.Verify(in)
+		assert.Nil(t, err)
+
+		in = httptest.NewRequest("POST", "/users/bob/inbox?page=1", strings.NewReader(testActivity))
+		in.Host = "mastodon.example"
+		in.Header = req.Header
+		_, err = verifier.Verify(in)
+		assert.True(t, xerrors.Is(err, httpsig.ErrInvalidSignature), err)
+
+		in.Header.Set("X-Forwarded-Proto", "https")
+		_, err = verifier.Verify(in)
+		assert.Nil(t, err)
+	})
+
+	t.Run("modified body", func(t *testing.T) {
+		req := sign(t, &httpsig.Signer{KeyID: "rsa"}, "POST", testActivity)
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"type": "Delete"}`))
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrDigestMismatch), err)
+	})
+
+	t.Run("modified target", func(t *testing.T) {
+		req := sign(t, &httpsig.Signer{KeyID: "rsa"}, "GET", "")
+		req.URL.RawQuery = "page=2"
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrInvalidSignature), err)
+	})
+
+	t.Run("missing content digest", func(t *testing.T) {
+		req := sign(t, &httpsig.Signer{KeyID: "rsa", Headers: httpsig.DefaultComponents}, "POST", testActivity)
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+	})
+
+	t.Run("clock skew", func(t *testing.T) {
+		req := sign(t, &httpsig.Signer{KeyID: "rsa"}, "GET", "")
+		v := *verifier
+		v.Now = func() time.Time { return testTime.Add(-2 * time.Minute) }
+		_, err := v.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrClockSkew), err)
+	})
+
+	t.Run("without created", func(t *testing.T) {
+		// signs a GET with the given components, and no created parameter
+		signWithout := func(t *testing.T, date string, components ...string) *http.Request {
+			req := newRequest(t, "GET", "https://mastodon.example/users/bob/inbox?page=1", "")
+			if len(date) > 0 {
+				req.Header.Set("Date", date)
+			}
+
+			quoted := make([]string, len(components))
+			var base strings.Builder
+			for i, c := range components {
+				quoted[i] = `"` + c + `"`
+				value := map[string]string{
+					"@method":     "GET",
+					"@target-uri": "https://mastodon.example/users/bob/inbox?page=1",
+					"date":        date,
+				}[c]
+				base.WriteString(`"` + c + `": ` + value + "\n")
+			}
+			params := "(" + strings.Join(quoted, " ") + `);keyid="ed25519";alg="ed25519"`
+			base.WriteString(`"@signature-params": ` + params)
+
+			sig := ed25519.Sign(testEd25519, []byte(base.String()))
+			req.Header.Set("Signature-Input", "sig1="+params)
+			req.Header.Set("Signature", "sig1=:"+base64.StdEncoding.EncodeToString(sig)+":")
+			return req
+		}
+		date := testTime.UTC().Format(http.TimeFormat)
+
+		keyID, err := verifier.Verify(signWithout(t, date, "@method", "@target-uri", "date"))
+		require.Nil(t, err)
+		assert.Equal(t, "ed25519", keyID)
+
+		_, err = verifier.Verify(signWithout(t, date, "@method", "@target-uri"))
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+
+		_, err = verifier.Verify(signWithout(t, "", "@method", "@target-uri"))
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+
+		_, err = verifier.Verify(signWithout(t, "", "@method", "@target-uri", "date"))
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+
+		stale := testTime.Add(-time.Hour).UTC().Format(http.TimeFormat)
+		_, err = verifier.Verify(signWithout(t, stale, "@method", "@target-uri", "date"))
+		assert.True(t, xerrors.Is(err, httpsig.ErrClockSkew), err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, input := range []string{
+			`sig1=("@method";created=1`,
+			`sig1=("@method");keyid="rsa`,
+			`sig1=("@method" "@query-param";name="x");keyid="rsa"`,
+			`Sig1=("@method")`,
+			`sig2=("@method");keyid="rsa"`,
+		} {
+			req := sign(t, &httpsig.Signer{KeyID: "rsa"}, "GET", "")
+			req.Header.Set("Signature-Input", input)
+			_, err := verifier.Verify(req)
+			assert.True(t, xerrors.Is(err, httpsig.ErrMalformedSignature), input)
+		}
+	})
+}
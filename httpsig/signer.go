@@ -0,0 +1,207 @@
+// Package httpsig signs and verifies HTTP requests with HTTP Signatures, as
+// used by ActivityPub servers like Mastodon and Pixelfed.
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	RequestTarget = "(request-target)"
+
+	algorithmRSA     = "rsa-sha256"
+	algorithmHS2019  = "hs2019"
+	algorithmRSAV15  = "rsa-v1_5-sha256"
+	algorithmEd25519 = "ed25519"
+)
+
+// DefaultHeaders are signed if a cavage Signer has no Headers. Digest is also
+// signed for requests with a body.
+var DefaultHeaders = []string{RequestTarget, "host", "date"}
+
+// Scheme is the HTTP signature specification used to sign a request.
+type Scheme int
+
+const (
+	// Cavage signs the Signature header, as described by
+	// draft-cavage-http-signatures. Most ActivityPub servers expect it.
+	Cavage Scheme = iota
+
+	// RFC9421 signs the Signature-Input and Signature headers, as described by
+	// RFC 9421 HTTP Message Signatures.
+	RFC9421
+)
+
+func (s Scheme) String() string {
+	switch s {
+	case Cavage:
+		return "cavage"
+	case RFC9421:
+		return "rfc9421"
+	default:
+		return fmt.Sprintf("Scheme(%d)", int(s))
+	}
+}
+
+// Signer signs requests with draft-cavage-http-signatures, or RFC 9421.
+type Signer struct {
+	KeyID string
+
+	// PrivateKey is an *rsa.PrivateKey or ed25519.PrivateKey.
+	PrivateKey crypto.PrivateKey
+
+	Scheme Scheme
+
+	// Headers are the lower case header names to sign, or component
+	// identifiers for RFC 9421. DefaultHeaders or DefaultComponents are used if
+	// empty.
+	Headers []string
+
+	// Expires sets the expiration of RFC 9421 signatures, if not zero.
+	Expires time.Duration
+
+	// Now returns the time for the Date header, and the created time of RFC
+	// 9421 signatures. time.Now is used if nil.
+	Now func() time.Time
+}
+
+// Sign sets the Date and Digest headers of the request if needed, and adds
+// the signature headers. The request body is read and restored.
+func (s *Signer) Sign(r *http.Request) error {
+	if len(r.Header.Get("Date")) == 0 {
+		r.Header.Set("Date", s.now().UTC().Format(http.TimeFormat))
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return xerrors.Errorf("httpsig: reading body: %w", err)
+	}
+
+	if s.Scheme == RFC9421 {
+		return s.signRFC9421(r, body)
+	}
+
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+		if body != nil {
+			headers = append(headers[:len(headers):len(headers)], "digest")
+		}
+	}
+
+	for _, h := range headers {
+		if strings.EqualFold(h, "digest") && len(r.Header.Get("Digest")) == 0 {
+			r.Header.Set("Digest", digest(body))
+		}
+	}
+
+	signing, err := signingString(r, headers)
+	if err != nil {
+		return err
+	}
+
+	sig, err := sign(s.PrivateKey, []byte(signing))
+	if err != nil {
+		return err
+	}
+
+	algorithm := algorithmRSA
+	if _, ok := s.PrivateKey.(ed25519.PrivateKey); ok {
+		algorithm = algorithmHS2019
+	}
+
+	r.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.KeyID, algorithm, strings.ToLower(strings.Join(headers, " ")),
+		base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+func (s *Signer) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func sign(key crypto.PrivateKey, data []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sum := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, data), nil
+	default:
+		return nil, xerrors.Errorf("httpsig: %T: %w", key, ErrUnsupportedKey)
+	}
+}
+
+// signingString builds the string that is signed from the given headers.
+func signingString(r *http.Request, headers []string) (string, error) {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		h = strings.ToLower(h)
+		switch h {
+		case RequestTarget:
+			lines[i] = fmt.Sprintf("%s: %s %s", h, strings.ToLower(r.Method), r.URL.RequestURI())
+		case "host":
+			lines[i] = "host: " + requestHost(r)
+		default:
+			values := r.Header.Values(h)
+			if len(values) == 0 {
+				return "", xerrors.Errorf("httpsig: %q: %w", h, ErrMissingHeader)
+			}
+			for j, v := range values {
+				values[j] = strings.TrimSpace(v)
+			}
+			lines[i] = h + ": " + strings.Join(values, ", ")
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// requestHost returns the host of the request. Outgoing requests usually only
+// set the URL, while incoming requests have the Host field.
+func requestHost(r *http.Request) string {
+	if len(r.Host) > 0 {
+		return r.Host
+	}
+	if h := r.Header.Get("Host"); len(h) > 0 {
+		return h
+	}
+	return r.URL.Host
+}
+
+// readBody reads the request body and replaces it, so that it can be read
+// again. It returns nil if the request has no body.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, err
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package httpsig_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub/httpsig"
+	"golang.org/x/xerrors"
+)
+
+var (
+	testRSAKey   *rsa.PrivateKey
+	testEd25519  ed25519.PrivateKey
+	testTime     = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	testActivity = `{"type": "Follow", "actor": "https://example.com/~erik"}`
+)
+
+func init() {
+	var err error
+	testRSAKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	_, testEd25519, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestSigner(t *testing.T) {
+	t.Run("rsa with body", func(t *testing.T) {
+		req := newRequest(t, "POST", "https://mastodon.example/users/bob/inbox?x=1", testActivity)
+		signer := &httpsig.Signer{
+			KeyID:      "https://example.com/~erik#main-key",
+			PrivateKey: testRSAKey,
+			Now:        func() time.Time { return testTime },
+		}
+		require.Nil(t, signer.Sign(req))
+
+		sum := sha256.Sum256([]byte(testActivity))
+		assert.Equal(t, "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]), req.Header.Get("Digest"))
+		assert.Equal(t, "Fri, 01 May 2020 12:00:00 GMT", req.Header.Get("Date"))
+
+		sig := req.Header.Get("Signature")
+		assert.True(t, strings.HasPrefix(sig, `keyId="https://example.com/~erik#main-key",algorithm="rsa-sha256",headers="(request-target) host date digest",signature="`), sig)
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.Nil(t, err)
+		assert.Equal(t, testActivity, string(body))
+	})
+
+	t.Run("ed25519 without body", func(t *testing.T) {
+		req := newRequest(t, "GET", "https://mastodon.example/users/bob", "")
+		req.Header.Set("Date", "Fri, 01 May 2020 11:00:00 GMT")
+		signer := &httpsig.Signer{KeyID: "key", PrivateKey: testEd25519}
+		require.Nil(t, signer.Sign(req))
+
+		assert.Equal(t, "", req.Header.Get("Digest"))
+		assert.Equal(t, "Fri, 01 May 2020 11:00:00 GMT", req.Header.Get("Date"))
+		assert.True(t, strings.HasPrefix(req.Header.Get("Signature"), `keyId="key",algorithm="hs2019",headers="(request-target) host date",`))
+	})
+
+	t.Run("custom headers", func(t *testing.T) {
+		req := newRequest(t, "POST", "https://mastodon.example/inbox", testActivity)
+		req.Header.Set("Content-Type", "application/activity+json")
+		signer := &httpsig.Signer{
+			KeyID:      "key",
+			PrivateKey: testRSAKey,
+			Headers:    []string{"(request-target)", "Content-Type", "digest"},
+		}
+		require.Nil(t, signer.Sign(req))
+		assert.True(t, strings.Contains(req.Header.Get("Signature"), `headers="(request-target) content-type digest"`))
+		assert.NotEqual(t, "", req.Header.Get("Digest"))
+
+		signer.Headers = []string{"x-missing"}
+		err := signer.Sign(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+	})
+
+	t.Run("unsupported key", func(t *testing.T) {
+		req := newRequest(t, "GET", "https://mastodon.example/users/bob", "")
+		signer := &httpsig.Signer{KeyID: "key", PrivateKey: "secret"}
+		err := signer.Sign(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrUnsupportedKey), err)
+	})
+}
+
+func newRequest(t *testing.T, method, url, body string) *http.Request {
+	var req *http.Request
+	var err error
+	if len(body) > 0 {
+		req, err = http.NewRequest(method, url, strings.NewReader(body))
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	require.Nil(t, err)
+	return req
+}
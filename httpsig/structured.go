@@ -0,0 +1,228 @@
+package httpsig
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// sfMember is a member of a structured field dictionary, as described by
+// RFC 8941. Only what is needed for the Signature-Input and Signature headers
+// is supported.
+type sfMember struct {
+	key string
+
+	// raw is the serialized value, including its parameters.
+	raw string
+
+	// value is the bare item, or items for an inner list.
+	value  string
+	items  []string
+	params map[string]string
+}
+
+type sfParser struct {
+	s string
+	i int
+}
+
+// parseDictionary parses a structured field dictionary, keeping the order of
+// its members.
+func parseDictionary(s string) ([]*sfMember, error) {
+	p := &sfParser{s: s}
+	var members []*sfMember
+	p.skipSpace()
+	for !p.done() {
+		m := &sfMember{}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		m.key = key
+
+		if p.peek() != '=' {
+			return nil, p.errorf("expected value for %q", key)
+		}
+		p.i++
+
+		start := p.i
+		if p.peek() == '(' {
+			m.items, err = p.innerList()
+		} else {
+			m.value, err = p.bareItem()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.params, err = p.parameters(); err != nil {
+			return nil, err
+		}
+		m.raw = p.s[start:p.i]
+		members = append(members, m)
+
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("expected comma")
+		}
+		p.i++
+		p.skipSpace()
+	}
+	return members, nil
+}
+
+func (p *sfParser) innerList() ([]string, error) {
+	p.i++
+	var items []string
+	for {
+		for p.peek() == ' ' {
+			p.i++
+		}
+		if p.done() {
+			return nil, p.errorf("unterminated inner list")
+		}
+		if p.peek() == ')' {
+			p.i++
+			return items, nil
+		}
+
+		item, err := p.bareItem()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == ';' {
+			return nil, p.errorf("parameters on %q are not supported", item)
+		}
+		items = append(items, item)
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			return nil, p.errorf("expected space or end of inner list")
+		}
+	}
+}
+
+func (p *sfParser) parameters() (map[string]string, error) {
+	params := make(map[string]string)
+	for p.peek() == ';' {
+		p.i++
+		for p.peek() == ' ' {
+			p.i++
+		}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		value := "?1"
+		if p.peek() == '=' {
+			p.i++
+			if value, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// bareItem returns a string, token, number, boolean, or byte sequence. Strings
+// are unescaped, and byte sequences are returned without their colons.
+func (p *sfParser) bareItem() (string, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		var b strings.Builder
+		for p.i++; !p.done(); p.i++ {
+			switch c := p.s[p.i]; c {
+			case '\\':
+				p.i++
+				if p.done() || (p.s[p.i] != '"' && p.s[p.i] != '\\') {
+					return "", p.errorf("invalid escape in string")
+				}
+				b.WriteByte(p.s[p.i])
+			case '"':
+				p.i++
+				return b.String(), nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", p.errorf("unterminated string")
+	case c == ':':
+		end := strings.IndexByte(p.s[p.i+1:], ':')
+		if end < 0 {
+			return "", p.errorf("unterminated byte sequence")
+		}
+		value := p.s[p.i+1 : p.i+1+end]
+		p.i += end + 2
+		return value, nil
+	case c == '?':
+		if p.i+1 >= len(p.s) || (p.s[p.i+1] != '0' && p.s[p.i+1] != '1') {
+			return "", p.errorf("invalid boolean")
+		}
+		p.i += 2
+		return p.s[p.i-2 : p.i], nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.i
+		for p.i++; !p.done() && (p.s[p.i] == '.' || (p.s[p.i] >= '0' && p.s[p.i] <= '9')); p.i++ {
+		}
+		return p.s[start:p.i], nil
+	case c == '*' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		start := p.i
+		for p.i++; !p.done() && isTokenChar(p.s[p.i]); p.i++ {
+		}
+		return p.s[start:p.i], nil
+	default:
+		return "", p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *sfParser) key() (string, error) {
+	if c := p.peek(); c != '*' && (c < 'a' || c > 'z') {
+		return "", p.errorf("invalid key")
+	}
+	start := p.i
+	for p.i++; !p.done(); p.i++ {
+		c := p.s[p.i]
+		if c != '_' && c != '-' && c != '.' && c != '*' && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			break
+		}
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *sfParser) skipSpace() {
+	for !p.done() && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+func (p *sfParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *sfParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *sfParser) errorf(format string, args ...interface{}) error {
+	return xerrors.Errorf("httpsig: %s at %d: %w", fmt.Sprintf(format, args...), p.i, ErrMalformedSignature)
+}
+
+func isTokenChar(c byte) bool {
+	if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~:/", c) >= 0
+}
+
+// sfString serializes a structured field string.
+func sfString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,124 @@
+package httpsig
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Transport is an http.RoundTripper that signs requests. It tries RFC 9421
+// signatures first, and falls back to cavage signatures if the host rejects
+// them with an Accept-Signature header, or a 401 response with a Signature
+// challenge. The scheme that works is remembered for each host. Requests with
+// a body are only retried if they have a GetBody function.
+type Transport struct {
+	// Signer is copied for each request, with the Scheme for the host.
+	Signer *Signer
+
+	// Base sends the signed requests. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+
+	mu      sync.Mutex
+	schemes map[string]Scheme
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	host := r.URL.Host
+	scheme, known := t.Scheme(host)
+	res, err := t.roundTrip(r, r.Body, scheme)
+	if err != nil || known {
+		return res, err
+	}
+
+	if !signatureRejected(res) {
+		t.setScheme(host, scheme)
+		return res, nil
+	}
+
+	body := r.Body
+	if body != nil && body != http.NoBody {
+		if r.GetBody == nil {
+			return res, nil
+		}
+		if body, err = r.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	res, err = t.roundTrip(r, body, Cavage)
+	if err != nil {
+		return res, err
+	}
+
+	// remember RFC 9421 if cavage signatures are rejected too, so that every
+	// request doesn't try both
+	if signatureRejected(res) {
+		t.setScheme(host, RFC9421)
+	} else {
+		t.setScheme(host, Cavage)
+	}
+	return res, nil
+}
+
+// Scheme returns the signature scheme used for the given host, and whether it
+// is known to work.
+func (t *Transport) Scheme(host string) (Scheme, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scheme, ok := t.schemes[host]
+	if !ok {
+		return RFC9421, false
+	}
+	return scheme, true
+}
+
+func (t *Transport) setScheme(host string, scheme Scheme) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.schemes == nil {
+		t.schemes = make(map[string]Scheme)
+	}
+	t.schemes[host] = scheme
+}
+
+func (t *Transport) roundTrip(r *http.Request, body io.ReadCloser, scheme Scheme) (*http.Response, error) {
+	req := r.Clone(r.Context())
+	req.Body = body
+
+	signer := *t.Signer
+	signer.Scheme = scheme
+	if err := signer.Sign(req); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// signatureRejected returns true if the response rejects the signature, rather
+// than the signer, with an Accept-Signature header or a Signature challenge.
+func signatureRejected(res *http.Response) bool {
+	if res.StatusCode != http.StatusUnauthorized && res.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if len(res.Header.Get("Accept-Signature")) > 0 {
+		return true
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	for _, challenge := range res.Header.Values("WWW-Authenticate") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(challenge)), "signature") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package httpsig_test
+
+import (
+	"context"
+	"crypto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub/httpsig"
+)
+
+func TestTransport(t *testing.T) {
+	verifier := &httpsig.Verifier{
+		Keys: httpsig.KeyGetterFunc(func(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+			return &testRSAKey.PublicKey, nil
+		}),
+	}
+
+	var schemes []string
+	handler := func(allowRFC9421 bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			scheme := "cavage"
+			if len(r.Header.Get("Signature-Input")) > 0 {
+				scheme = "rfc9421"
+			}
+			schemes = append(schemes, scheme)
+
+			if scheme == "rfc9421" && !allowRFC9421 {
+				w.Header().Set("WWW-Authenticate", `Signature realm="legacy",headers="(request-target) host date digest"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if _, err := verifier.Verify(r); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Write(body)
+		}
+	}
+
+	modern := httptest.NewServer(handler(true))
+	defer modern.Close()
+	legacy := httptest.NewServer(handler(false))
+	defer legacy.Close()
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		schemes = append(schemes, "forbidden")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+
+	transport := &httpsig.Transport{Signer: &httpsig.Signer{KeyID: "rsa", PrivateKey: testRSAKey}}
+	client := &http.Client{Transport: transport}
+
+	post := func(t *testing.T, server *httptest.Server) {
+		res, err := client.Post(server.URL+"/inbox", "application/activity+json", strings.NewReader(testActivity))
+		require.Nil(t, err)
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode, string(body))
+		assert.Equal(t, testActivity, string(body))
+	}
+
+	host := func(server *httptest.Server) string {
+		u, err := url.Parse(server.URL)
+		require.Nil(t, err)
+		return u.Host
+	}
+
+	t.Run("rfc9421", func(t *testing.T) {
+		schemes = nil
+		scheme, ok := transport.Scheme(host(modern))
+		assert.Equal(t, httpsig.RFC9421, scheme)
+		assert.False(t, ok)
+
+		post(t, modern)
+		post(t, modern)
+		assert.Equal(t, []string{"rfc9421", "rfc9421"}, schemes)
+
+		scheme, ok = transport.Scheme(host(modern))
+		assert.Equal(t, httpsig.RFC9421, scheme)
+		assert.True(t, ok)
+	})
+
+	t.Run("fallback to cavage", func(t *testing.T) {
+		schemes = nil
+		post(t, legacy)
+		post(t, legacy)
+		assert.Equal(t, []string{"rfc9421", "cavage", "cavage"}, schemes)
+
+		scheme, ok := transport.Scheme(host(legacy))
+		assert.Equal(t, httpsig.Cavage, scheme)
+		assert.True(t, ok)
+		assert.Equal(t, "cavage", scheme.String())
+	})
+
+	t.Run("authorization failure", func(t *testing.T) {
+		schemes = nil
+		for i := 0; i < 2; i++ {
+			res, err := client.Post(forbidden.URL+"/inbox", "application/activity+json", strings.NewReader(testActivity))
+			require.Nil(t, err)
+			res.Body.Close()
+			assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		}
+		assert.Equal(t, []string{"forbidden", "forbidden"}, schemes)
+
+		scheme, ok := transport.Scheme(host(forbidden))
+		assert.Equal(t, httpsig.RFC9421, scheme)
+		assert.True(t, ok)
+	})
+
+	t.Run("body without GetBody", func(t *testing.T) {
+		schemes = nil
+		transport := &httpsig.Transport{Signer: transport.Signer}
+		client := &http.Client{Transport: transport}
+		body := ioutil.NopCloser(strings.NewReader(testActivity))
+		res, err := client.Post(legacy.URL+"/inbox", "application/activity+json", body)
+		require.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, []string{"rfc9421"}, schemes)
+
+		_, ok := transport.Scheme(host(legacy))
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,263 @@
+package httpsig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultMaxSkew is the clock skew allowed by a Verifier without a MaxSkew.
+// Mastodon allows the same.
+const DefaultMaxSkew = 12 * time.Hour
+
+// KeyGetter looks up the public key of a signature's keyId, such as the
+// publicKey of an ActivityPub actor.
+type KeyGetter interface {
+	GetKey(ctx context.Context, keyID string) (crypto.PublicKey, error)
+}
+
+// KeyGetterFunc adapts a function to a KeyGetter.
+type KeyGetterFunc func(ctx context.Context, keyID string) (crypto.PublicKey, error)
+
+func (f KeyGetterFunc) GetKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	return f(ctx, keyID)
+}
+
+// Verifier verifies signed requests.
+type Verifier struct {
+	Keys KeyGetter
+
+	// MaxSkew is how far the Date header, or the created time of RFC 9421
+	// signatures, may be from the current time.
+	// DefaultMaxSkew is used if zero.
+	MaxSkew time.Duration
+
+	// RequiredHeaders must be covered by the signature. DefaultHeaders, or
+	// DefaultComponents for RFC 9421 signatures, are required if empty. Digest
+	// or Content-Digest is also required for requests with a body.
+	RequiredHeaders []string
+
+	// Now returns the current time. time.Now is used if nil.
+	Now func() time.Time
+}
+
+// Verify checks the request signature, and returns the keyId that signed it.
+// RFC 9421 signatures are verified if the request has a Signature-Input
+// header. Cavage signatures need a Date header, and requests with a body need
+// a signed Digest or Content-Digest. The request body is read and restored.
+func (v *Verifier) Verify(r *http.Request) (string, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return "", xerrors.Errorf("httpsig: reading body: %w", err)
+	}
+
+	if len(r.Header.Get("Signature-Input")) > 0 {
+		return v.verifyRFC9421(r, body)
+	}
+	return v.verifyCavage(r, body)
+}
+
+func (v *Verifier) verifyCavage(r *http.Request, body []byte) (string, error) {
+	params, err := parseSignature(r)
+	if err != nil {
+		return "", err
+	}
+
+	keyID := params["keyId"]
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil || len(keyID) == 0 || len(sig) == 0 {
+		return keyID, xerrors.Errorf("httpsig: missing keyId or signature: %w", ErrMalformedSignature)
+	}
+
+	headers := []string{"date"}
+	if h, ok := params["headers"]; ok {
+		headers = strings.Fields(strings.ToLower(h))
+	}
+
+	required := v.RequiredHeaders
+	if len(required) == 0 {
+		required = DefaultHeaders
+	}
+	if body != nil {
+		required = append(required[:len(required):len(required)], "digest")
+	}
+	if err := checkCovered(headers, required); err != nil {
+		return keyID, err
+	}
+
+	if err := v.checkDate(r); err != nil {
+		return keyID, err
+	}
+	if err := checkDigest(r, body); err != nil {
+		return keyID, err
+	}
+
+	signing, err := signingString(r, headers)
+	if err != nil {
+		return keyID, err
+	}
+
+	key, err := v.Keys.GetKey(r.Context(), keyID)
+	if err != nil {
+		return keyID, xerrors.Errorf("httpsig: key %q: %w", keyID, err)
+	}
+
+	if err := verify(key, params["algorithm"], []byte(signing), sig); err != nil {
+		return keyID, xerrors.Errorf("httpsig: key %q: %w", keyID, err)
+	}
+	return keyID, nil
+}
+
+// checkCovered returns an error if any required headers are not signed.
+func checkCovered(signed, required []string) error {
+	covered := make(map[string]bool, len(signed))
+	for _, h := range signed {
+		covered[h] = true
+	}
+	for _, h := range required {
+		if !covered[strings.ToLower(h)] {
+			return xerrors.Errorf("httpsig: %q: %w", h, ErrMissingHeader)
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) checkDate(r *http.Request) error {
+	date := r.Header.Get("Date")
+	if len(date) == 0 {
+		return xerrors.Errorf("httpsig: %q: %w", "date", ErrMissingHeader)
+	}
+
+	t, err := http.ParseTime(date)
+	if err != nil {
+		return xerrors.Errorf("httpsig: date %q: %w", date, ErrClockSkew)
+	}
+
+	skew := v.maxSkew()
+	if d := v.now().Sub(t); d > skew || d < -skew {
+		return xerrors.Errorf("httpsig: date %q: %w", date, ErrClockSkew)
+	}
+	return nil
+}
+
+func (v *Verifier) maxSkew() time.Duration {
+	if v.MaxSkew > 0 {
+		return v.MaxSkew
+	}
+	return DefaultMaxSkew
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// checkDigest compares the body against the Digest header, which is required
+// for requests with a body.
+func checkDigest(r *http.Request, body []byte) error {
+	header := r.Header.Get("Digest")
+	if len(header) == 0 {
+		if body == nil {
+			return nil
+		}
+		return xerrors.Errorf("httpsig: %q: %w", "digest", ErrMissingHeader)
+	}
+
+	for _, d := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(d), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		var sum []byte
+		switch strings.ToUpper(parts[0]) {
+		case "SHA-256":
+			s := sha256.Sum256(body)
+			sum = s[:]
+		case "SHA-512":
+			s := sha512.Sum512(body)
+			sum = s[:]
+		default:
+			continue
+		}
+
+		expected, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || subtle.ConstantTimeCompare(expected, sum) != 1 {
+			return xerrors.Errorf("httpsig: %q: %w", header, ErrDigestMismatch)
+		}
+		return nil
+	}
+	return xerrors.Errorf("httpsig: %q: %w", header, ErrUnsupportedAlgorithm)
+}
+
+func verify(key crypto.PublicKey, algorithm string, data, sig []byte) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if len(algorithm) > 0 && algorithm != algorithmRSA && algorithm != algorithmRSAV15 && algorithm != algorithmHS2019 {
+			return xerrors.Errorf("%q: %w", algorithm, ErrUnsupportedAlgorithm)
+		}
+		sum := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case ed25519.PublicKey:
+		if len(algorithm) > 0 && algorithm != algorithmEd25519 && algorithm != algorithmHS2019 {
+			return xerrors.Errorf("%q: %w", algorithm, ErrUnsupportedAlgorithm)
+		}
+		if !ed25519.Verify(k, data, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return xerrors.Errorf("%T: %w", key, ErrUnsupportedKey)
+	}
+}
+
+// parseSignature parses the Signature header, or an Authorization header with
+// the Signature scheme.
+func parseSignature(r *http.Request) (map[string]string, error) {
+	header := r.Header.Get("Signature")
+	if len(header) == 0 {
+		auth := r.Header.Get("Authorization")
+		if len(auth) < 10 || !strings.EqualFold(auth[:10], "signature ") {
+			return nil, ErrNoSignature
+		}
+		header = auth[10:]
+	}
+
+	params := make(map[string]string)
+	for len(header) > 0 {
+		eq := strings.IndexByte(header, '=')
+		if eq < 0 {
+			return nil, xerrors.Errorf("httpsig: %q: %w", header, ErrMalformedSignature)
+		}
+		name := strings.TrimSpace(header[:eq])
+		header = strings.TrimSpace(header[eq+1:])
+
+		if len(header) == 0 || header[0] != '"' {
+			return nil, xerrors.Errorf("httpsig: %s: %w", name, ErrMalformedSignature)
+		}
+		end := strings.IndexByte(header[1:], '"')
+		if end < 0 {
+			return nil, xerrors.Errorf("httpsig: %s: %w", name, ErrMalformedSignature)
+		}
+		params[name] = header[1 : end+1]
+
+		header = strings.TrimSpace(header[end+2:])
+		header = strings.TrimPrefix(header, ",")
+	}
+	return params, nil
+}
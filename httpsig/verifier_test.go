@@ -0,0 +1,173 @@
+package httpsig_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub/httpsig"
+	"golang.org/x/xerrors"
+)
+
+func TestVerifier(t *testing.T) {
+	keys := map[string]crypto.PublicKey{
+		"rsa":     &testRSAKey.PublicKey,
+		"ed25519": testEd25519.Public(),
+	}
+	verifier := &httpsig.Verifier{
+		Keys: httpsig.KeyGetterFunc(func(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+			if key, ok := keys[keyID]; ok {
+				return key, nil
+			}
+			return nil, errors.New("no key")
+		}),
+		MaxSkew: time.Minute,
+		Now:     func() time.Time { return testTime.Add(30 * time.Second) },
+	}
+
+	signed := func(t *testing.T, keyID, method, body string) *http.Request {
+		req := newRequest(t, method, "https://mastodon.example/users/bob/inbox", body)
+		signer := &httpsig.Signer{
+			KeyID:      keyID,
+			PrivateKey: testRSAKey,
+			Now:        func() time.Time { return testTime },
+		}
+		if keyID == "ed25519" {
+			signer.PrivateKey = testEd25519
+		}
+		require.Nil(t, signer.Sign(req))
+		return req
+	}
+
+	for _, keyID := range []string{"rsa", "ed25519"} {
+		t.Run(keyID, func(t *testing.T) {
+			req := signed(t, keyID, "POST", testActivity)
+			id, err := verifier.Verify(req)
+			require.Nil(t, err)
+			assert.Equal(t, keyID, id)
+
+			body, err := ioutil.ReadAll(req.Body)
+			require.Nil(t, err)
+			assert.Equal(t, testActivity, string(body))
+		})
+	}
+
+	t.Run("authorization header", func(t *testing.T) {
+		req := signed(t, "rsa", "GET", "")
+		req.Header.Set("Authorization", "Signature "+req.Header.Get("Signature"))
+		req.Header.Del("Signature")
+		_, err := verifier.Verify(req)
+		assert.Nil(t, err)
+	})
+
+	t.Run("not signed", func(t *testing.T) {
+		req := newRequest(t, "GET", "https://mastodon.example/users/bob", "")
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrNoSignature), err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		req := signed(t, "rsa", "GET", "")
+		req.Header.Set("Signature", `keyId="rsa",signature=abc`)
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrMalformedSignature), err)
+	})
+
+	t.Run("modified body", func(t *testing.T) {
+		req := signed(t, "rsa", "POST", testActivity)
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"type": "Delete"}`))
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrDigestMismatch), err)
+	})
+
+	t.Run("modified digest", func(t *testing.T) {
+		req := signed(t, "ed25519", "POST", testActivity)
+		body := `{"type": "Delete"}`
+		sum := sha256.Sum256([]byte(body))
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrInvalidSignature), err)
+	})
+
+	t.Run("modified path", func(t *testing.T) {
+		req := signed(t, "rsa", "GET", "")
+		req.URL.Path = "/users/erik/inbox"
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrInvalidSignature), err)
+	})
+
+	t.Run("missing digest", func(t *testing.T) {
+		req := newRequest(t, "POST", "https://mastodon.example/users/bob/inbox", testActivity)
+		signer := &httpsig.Signer{
+			KeyID:      "rsa",
+			PrivateKey: testRSAKey,
+			Headers:    httpsig.DefaultHeaders,
+			Now:        func() time.Time { return testTime },
+		}
+		require.Nil(t, signer.Sign(req))
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+	})
+
+	t.Run("custom required headers", func(t *testing.T) {
+		v := *verifier
+		v.RequiredHeaders = []string{"(request-target)"}
+		sign := func(method, body string) *http.Request {
+			req := newRequest(t, method, "https://mastodon.example/users/bob/inbox", body)
+			signer := &httpsig.Signer{
+				KeyID:      "rsa",
+				PrivateKey: testRSAKey,
+				Headers:    []string{"(request-target)", "host"},
+				Now:        func() time.Time { return testTime },
+			}
+			require.Nil(t, signer.Sign(req))
+			return req
+		}
+
+		_, err := v.Verify(sign("POST", testActivity))
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+
+		req := sign("GET", "")
+		_, err = v.Verify(req)
+		require.Nil(t, err)
+
+		req.Header.Del("Date")
+		_, err = v.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrMissingHeader), err)
+	})
+
+	t.Run("clock skew", func(t *testing.T) {
+		req := signed(t, "rsa", "GET", "")
+		v := *verifier
+		v.Now = func() time.Time { return testTime.Add(-2 * time.Minute) }
+		_, err := v.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrClockSkew), err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		req := signed(t, "rsa", "GET", "")
+		req.Header.Set("Signature", strings.Replace(req.Header.Get("Signature"), `keyId="rsa"`, `keyId="other"`, 1))
+		id, err := verifier.Verify(req)
+		assert.Equal(t, "other", id)
+		assert.Equal(t, `httpsig: key "other": no key`, err.Error())
+	})
+
+	t.Run("wrong key type", func(t *testing.T) {
+		req := signed(t, "rsa", "GET", "")
+		keys["rsa"] = ed25519.PublicKey(keys["ed25519"].(ed25519.PublicKey))
+		defer func() { keys["rsa"] = &testRSAKey.PublicKey }()
+		_, err := verifier.Verify(req)
+		assert.True(t, xerrors.Is(err, httpsig.ErrUnsupportedAlgorithm), err)
+	})
+}
@@ -0,0 +1,193 @@
+package apub
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultMaxBodySize is the largest activity accepted by an InboxHandler
+// without a MaxBodySize.
+const DefaultMaxBodySize = 1 << 20
+
+// ActivityFunc handles an activity posted to an inbox.
+type ActivityFunc func(ctx context.Context, act *Object) error
+
+// SignatureVerifier checks the signature of a request, and returns the id of
+// the key that signed it. *httpsig.Verifier implements it.
+type SignatureVerifier interface {
+	Verify(r *http.Request) (string, error)
+}
+
+// InboxHandler is an http.Handler for an ActivityPub inbox. It verifies and
+// parses posted activities, and dispatches them by type to the functions
+// registered with Handle.
+type InboxHandler struct {
+	// Verifier checks the HTTP signature of each request. Requests are not
+	// verified if nil. The signing key is only checked against the activity's
+	// actor by Origins, so set both to authenticate the actor.
+	Verifier SignatureVerifier
+
+	// MaxBodySize is the largest accepted request body. DefaultMaxBodySize is
+	// used if zero.
+	MaxBodySize int64
+
+	// Parser parses the posted activities. A zero Parser is used if nil.
+	Parser *Parser
+
+	// Origins checks that activities could have come from their actor, and
+	// were signed by them. Activities that fail are rejected with 403
+	// Forbidden, and activities without an actor with 400 Bad Request. Other
+	// failures, like fetching the actor's key, return 503 Service Unavailable
+	// so that the sender retries. Activities are not checked if nil.
+	Origins *OriginChecker
+
+	// Fallback handles activities without a registered function. They are
+	// accepted and ignored if nil.
+	Fallback ActivityFunc
+
+	handlers map[string]ActivityFunc
+}
+
+// Handle registers the function for activities of the given type, such as
+// "Follow" or "Create".
+func (h *InboxHandler) Handle(ty string, fn ActivityFunc) {
+	if h.handlers == nil {
+		h.handlers = make(map[string]ActivityFunc)
+	}
+	h.handlers[ty] = fn
+}
+
+func (h *InboxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, status := readActivity(w, r, h.MaxBodySize)
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	ctx := r.Context()
+	if h.Verifier != nil {
+		keyID, err := h.Verifier.Verify(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		ctx = WithSignerKeyID(ctx, keyID)
+	}
+
+	act, err := parseActivity(h.Parser, body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if h.Origins != nil {
+		if err := h.Origins.Check(ctx, act); err != nil {
+			status := originStatus(err)
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+
+	fn := h.handlers[act.Type()]
+	if fn == nil {
+		fn = h.Fallback
+	}
+	if fn != nil {
+		if err := fn(ctx, act); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// originStatus returns the status code for an error from OriginChecker.Check.
+func originStatus(err error) int {
+	var oerr *OriginError
+	switch {
+	case xerrors.As(err, &oerr), xerrors.Is(err, ErrOriginMismatch),
+		xerrors.Is(err, ErrKeyNotFound), xerrors.Is(err, ErrKeyOwnerMismatch):
+		return http.StatusForbidden
+	case xerrors.Is(err, ErrMissingActor), xerrors.Is(err, ErrInvalidIDs):
+		return http.StatusBadRequest
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// readActivity reads the body of a posted activity, and replaces it so that
+// it can be read again. It returns the status code to respond with if the
+// request is not acceptable.
+func readActivity(w http.ResponseWriter, r *http.Request, max int64) ([]byte, int) {
+	if !isActivityMediaType(r.Header.Get("Content-Type")) {
+		return nil, http.StatusUnsupportedMediaType
+	}
+
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	if r.ContentLength > max {
+		return nil, http.StatusRequestEntityTooLarge
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, max))
+	if err != nil {
+		return nil, http.StatusRequestEntityTooLarge
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, 0
+}
+
+// parseActivity parses a posted activity, which must have a type.
+func parseActivity(p *Parser, body []byte) (*Object, error) {
+	if p == nil {
+		p = &Parser{}
+	}
+	act, err := p.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(act.Type()) == 0 {
+		return nil, ErrMissingType
+	}
+	return act, nil
+}
+
+// isActivityMediaType returns true for application/activity+json, and
+// application/ld+json, which should have the ActivityStreams profile.
+func isActivityMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	mediaType = strings.ToLower(mediaType)
+	return mediaType == "application/activity+json" || mediaType == "application/ld+json"
+}
+
+type signerKeyIDKey struct{}
+
+// WithSignerKeyID returns a context with the id of the key that signed the
+// request.
+func WithSignerKeyID(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, signerKeyIDKey{}, keyID)
+}
+
+// SignerKeyID returns the id of the key that signed the request, as set by an
+// InboxHandler with a Verifier.
+func SignerKeyID(ctx context.Context) string {
+	keyID, _ := ctx.Value(signerKeyIDKey{}).(string)
+	return keyID
+}
@@ -0,0 +1,150 @@
+package apub_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"github.com/technoweenie/apub/httpsig"
+	"golang.org/x/xerrors"
+)
+
+func TestInboxHandler(t *testing.T) {
+	var handled []string
+	inbox := &apub.InboxHandler{MaxBodySize: 256}
+	inbox.Handle("Follow", func(ctx context.Context, act *apub.Object) error {
+		handled = append(handled, act.Type()+" "+act.Str("object")+" "+apub.SignerKeyID(ctx))
+		return nil
+	})
+	inbox.Handle("Undo", func(ctx context.Context, act *apub.Object) error {
+		return errors.New("boom")
+	})
+
+	post := func(t *testing.T, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/users/erik/inbox", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		res := httptest.NewRecorder()
+		inbox.ServeHTTP(res, req)
+		return res
+	}
+
+	follow := `{"type": "Follow", "actor": "https://example.com/~bob", "object": "https://example.com/~erik"}`
+
+	t.Run("dispatch", func(t *testing.T) {
+		handled = nil
+		for _, ct := range []string{
+			"application/activity+json",
+			`application/ld+json; profile="https://www.w3.org/ns/activitystreams"`,
+		} {
+			res := post(t, ct, follow)
+			assert.Equal(t, http.StatusAccepted, res.Code, ct)
+		}
+		assert.Equal(t, []string{
+			"Follow https://example.com/~erik ",
+			"Follow https://example.com/~erik ",
+		}, handled)
+	})
+
+	t.Run("unhandled type", func(t *testing.T) {
+		handled = nil
+		res := post(t, "application/activity+json", `{"type": "Like"}`)
+		assert.Equal(t, http.StatusAccepted, res.Code)
+		assert.Equal(t, 0, len(handled))
+
+		inbox.Fallback = func(ctx context.Context, act *apub.Object) error {
+			handled = append(handled, act.Type())
+			return nil
+		}
+		defer func() { inbox.Fallback = nil }()
+		res = post(t, "application/activity+json", `{"type": "Like"}`)
+		assert.Equal(t, http.StatusAccepted, res.Code)
+		assert.Equal(t, []string{"Like"}, handled)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		res := post(t, "application/activity+json", `{"type": "Undo"}`)
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		res := post(t, "application/json", follow)
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+		res = post(t, "application/activity+json", `{"type": "Follow", "content": "`+strings.Repeat("a", 256)+`"}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+		res = post(t, "application/activity+json", `{"type": "Follow"`)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		res = post(t, "application/activity+json", `{"actor": "https://example.com/~bob"}`)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		req := httptest.NewRequest("GET", "/users/erik/inbox", nil)
+		rec := httptest.NewRecorder()
+		inbox.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "POST", rec.Header().Get("Allow"))
+	})
+
+	t.Run("origins", func(t *testing.T) {
+		inbox.Origins = &apub.OriginChecker{}
+		defer func() { inbox.Origins = nil }()
+
+		handled = nil
+		res := post(t, "application/activity+json", follow)
+		assert.Equal(t, http.StatusAccepted, res.Code)
+
+		res = post(t, "application/activity+json",
+			`{"id": "https://evil.example/follow", "type": "Follow", "actor": "https://example.com/~bob"}`)
+		assert.Equal(t, http.StatusForbidden, res.Code)
+
+		res = post(t, "application/activity+json", `{"type": "Follow"}`)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, 1, len(handled))
+
+		// failed fetches can be retried
+		inbox.Origins.Fetcher = apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			return nil, xerrors.Errorf("Fetch: %q: timeout: %w", iri, apub.ErrFetchFailed)
+		})
+		res = post(t, "application/activity+json", `{
+			"type": "Announce",
+			"actor": "https://example.com/~bob",
+			"object": {"id": "https://b.example/note/1", "type": "Note"}
+		}`)
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	})
+
+	t.Run("signatures", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.Nil(t, err)
+		inbox.Verifier = &httpsig.Verifier{
+			Keys: httpsig.KeyGetterFunc(func(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+				return &key.PublicKey, nil
+			}),
+		}
+		defer func() { inbox.Verifier = nil }()
+
+		res := post(t, "application/activity+json", follow)
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+		handled = nil
+		req := httptest.NewRequest("POST", "https://example.com/users/erik/inbox", strings.NewReader(follow))
+		req.Header.Set("Content-Type", "application/activity+json")
+		signer := &httpsig.Signer{KeyID: "https://example.com/~bob#main-key", PrivateKey: key}
+		require.Nil(t, signer.Sign(req))
+
+		rec := httptest.NewRecorder()
+		inbox.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, []string{"Follow https://example.com/~erik https://example.com/~bob#main-key"}, handled)
+	})
+}
@@ -0,0 +1,618 @@
+package apub
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Expand returns the expanded JSON-LD form of the object, with every property
+// and type as an absolute IRI and every value as an array. Documents that
+// expand to several top-level nodes are returned in an @graph.
+func Expand(o *Object) (*Object, error) {
+	nodes, err := expandObject(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	switch len(nodes) {
+	case 0:
+		data = make(map[string]interface{})
+	case 1:
+		data, _ = nodes[0].(map[string]interface{})
+	default:
+		data = map[string]interface{}{"@graph": nodes}
+	}
+
+	obj := New(data)
+	obj.lang = o.lang
+	return obj, nil
+}
+
+// Compact expands the object and compacts it again with the given @context,
+// such as ActivityStreamsContext.
+func Compact(o *Object, ctx interface{}) (*Object, error) {
+	nodes, err := expandObject(o)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := newActiveContext(o.loader()).parse(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("Compact: %w", err)
+	}
+
+	var data map[string]interface{}
+	switch compacted := active.compact("", nodes).(type) {
+	case map[string]interface{}:
+		data = compacted
+	case []interface{}:
+		data = make(map[string]interface{})
+		if len(compacted) > 0 {
+			data[active.compactIRI("@graph", true)] = compacted
+		}
+	default:
+		data = make(map[string]interface{})
+	}
+
+	if ctx != nil {
+		data["@context"] = ctx
+	}
+
+	obj := New(data)
+	obj.lang = o.lang
+	obj.setContext(active)
+	return obj, nil
+}
+
+func expandObject(o *Object) ([]interface{}, error) {
+	active := o.ctx
+	if active == nil {
+		active = newActiveContext(o.loader())
+	}
+
+	data := o.data
+	if o.ctx != nil {
+		// the root @context was already processed when the object was parsed
+		data = withoutKey(data, "@context")
+	}
+
+	expanded, err := active.expand("", data)
+	if err != nil {
+		return nil, xerrors.Errorf("Expand: %w", err)
+	}
+
+	if m, ok := expanded.(map[string]interface{}); ok && len(m) == 1 {
+		if graph, ok := m["@graph"]; ok {
+			expanded = graph
+		}
+	}
+	return asList(expanded), nil
+}
+
+func (o *Object) loader() DocumentLoader {
+	if o.ctx != nil {
+		return o.ctx.loader
+	}
+	return nil
+}
+
+func (c *activeContext) expand(prop string, element interface{}) (interface{}, error) {
+	switch val := element.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		result := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			expanded, err := c.expand(prop, item)
+			if err != nil {
+				return nil, err
+			}
+			if expanded != nil {
+				result = append(result, asList(expanded)...)
+			}
+		}
+		return result, nil
+	case map[string]interface{}:
+		return c.expandMap(prop, val)
+	default:
+		if len(prop) == 0 || prop == "@graph" {
+			return nil, nil
+		}
+		return c.expandValue(prop, val), nil
+	}
+}
+
+func (c *activeContext) expandMap(prop string, element map[string]interface{}) (interface{}, error) {
+	if local, ok := element["@context"]; ok {
+		ctx, err := c.parse(local)
+		if err != nil {
+			return nil, err
+		}
+		c = ctx
+	}
+
+	result := make(map[string]interface{})
+	for _, key := range sortedKeys(element) {
+		value := element[key]
+		if key == "@context" {
+			continue
+		}
+
+		expProp := c.expandTerm(key)
+		if len(expProp) == 0 || (!isKeyword(expProp) && !strings.Contains(expProp, ":")) {
+			continue
+		}
+
+		if isKeyword(expProp) {
+			if err := c.expandKeyword(prop, expProp, value, result); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		def := c.terms[key]
+		if def != nil && def.reverse {
+			continue
+		}
+
+		var expanded interface{}
+		if langMap, ok := value.(map[string]interface{}); ok && def != nil && def.container == "@language" {
+			expanded = expandLanguageMap(langMap)
+		} else {
+			var err error
+			expanded, err = c.expand(key, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if expanded == nil {
+			continue
+		}
+
+		if def != nil && def.container == "@list" && !isListObject(expanded) {
+			expanded = map[string]interface{}{"@list": asList(expanded)}
+		}
+
+		existing, _ := result[expProp].([]interface{})
+		result[expProp] = append(existing, asList(expanded)...)
+	}
+
+	if val, ok := result["@value"]; ok {
+		if val == nil {
+			return nil, nil
+		}
+		if types, ok := result["@type"].([]interface{}); ok && len(types) > 0 {
+			result["@type"] = types[0]
+		}
+		return result, nil
+	}
+
+	if set, ok := result["@set"]; ok {
+		return set, nil
+	}
+
+	if _, ok := result["@language"]; ok && len(result) == 1 {
+		return nil, nil
+	}
+
+	if len(prop) == 0 || prop == "@graph" {
+		_, hasID := result["@id"]
+		_, hasList := result["@list"]
+		if len(result) == 0 || hasList || (hasID && len(result) == 1) {
+			return nil, nil
+		}
+	}
+
+	return result, nil
+}
+
+func (c *activeContext) expandKeyword(prop, keyword string, value interface{}, result map[string]interface{}) error {
+	switch keyword {
+	case "@id":
+		id, ok := value.(string)
+		if !ok {
+			return xerrors.Errorf("Expand: @id %T %+v: %w", value, value, ErrInvalidIDs)
+		}
+		iri, _ := c.expandIRI(id, true, false, nil, nil)
+		result["@id"] = iri
+	case "@type":
+		var types []interface{}
+		for _, t := range asList(value) {
+			if s, ok := t.(string); ok {
+				iri, _ := c.expandIRI(s, true, true, nil, nil)
+				types = append(types, iri)
+			}
+		}
+		result["@type"] = types
+	case "@graph":
+		expanded, err := c.expand("@graph", value)
+		if err != nil {
+			return err
+		}
+		result["@graph"] = asList(expanded)
+	case "@list", "@set":
+		expanded, err := c.expand(prop, value)
+		if err != nil {
+			return err
+		}
+		result[keyword] = asList(expanded)
+	case "@language":
+		if s, ok := value.(string); ok {
+			result["@language"] = strings.ToLower(s)
+		}
+	case "@value", "@index":
+		result[keyword] = value
+	}
+	return nil
+}
+
+func (c *activeContext) expandValue(prop string, value interface{}) interface{} {
+	def := c.terms[prop]
+	if s, ok := value.(string); ok && def != nil {
+		switch def.typ {
+		case "@id":
+			iri, _ := c.expandIRI(s, true, false, nil, nil)
+			return map[string]interface{}{"@id": iri}
+		case "@vocab":
+			iri, _ := c.expandIRI(s, true, true, nil, nil)
+			return map[string]interface{}{"@id": iri}
+		}
+	}
+
+	result := map[string]interface{}{"@value": value}
+	if def != nil && len(def.typ) > 0 && def.typ != "@id" && def.typ != "@vocab" && def.typ != "@none" {
+		result["@type"] = def.typ
+	} else if _, ok := value.(string); ok && len(c.language) > 0 {
+		result["@language"] = c.language
+	}
+	return result
+}
+
+func expandLanguageMap(langMap map[string]interface{}) interface{} {
+	var result []interface{}
+	for _, lang := range sortedKeys(langMap) {
+		for _, item := range asList(langMap[lang]) {
+			s, ok := item.(string)
+			if !ok {
+				continue
+			}
+			v := map[string]interface{}{"@value": s}
+			if lang != "@none" {
+				v["@language"] = strings.ToLower(lang)
+			}
+			result = append(result, v)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func (c *activeContext) compact(prop string, element interface{}) interface{} {
+	switch val := element.(type) {
+	case []interface{}:
+		result := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			if compacted := c.compact(prop, item); compacted != nil {
+				result = append(result, compacted)
+			}
+		}
+		if len(result) == 1 && !c.isArrayContainer(prop) {
+			return result[0]
+		}
+		return result
+	case map[string]interface{}:
+		return c.compactMap(prop, val)
+	default:
+		return element
+	}
+}
+
+func (c *activeContext) compactMap(prop string, element map[string]interface{}) interface{} {
+	if v, ok := c.compactValue(prop, element); ok {
+		return v
+	}
+
+	if list, ok := element["@list"]; ok {
+		items := c.compact(prop, list)
+		if c.isArrayContainer(prop) {
+			return asList(items)
+		}
+		return map[string]interface{}{c.compactIRI("@list", true): asList(items)}
+	}
+
+	result := make(map[string]interface{})
+	for _, expProp := range sortedKeys(element) {
+		value := element[expProp]
+		switch expProp {
+		case "@id":
+			id, _ := value.(string)
+			result[c.compactIRI("@id", true)] = c.compactIRI(id, false)
+			continue
+		case "@type":
+			var types []interface{}
+			for _, t := range asList(value) {
+				if s, ok := t.(string); ok {
+					types = append(types, c.compactIRI(s, true))
+				}
+			}
+			if len(types) == 1 {
+				result[c.compactIRI("@type", true)] = types[0]
+			} else {
+				result[c.compactIRI("@type", true)] = types
+			}
+			continue
+		case "@graph":
+			result[c.compactIRI("@graph", true)] = asList(c.compact("@graph", value))
+			continue
+		case "@index", "@language", "@value":
+			result[c.compactIRI(expProp, true)] = value
+			continue
+		}
+		if isKeyword(expProp) {
+			continue
+		}
+
+		for _, item := range asList(value) {
+			term := c.selectTerm(expProp, item)
+			def := c.terms[term]
+			compacted := c.compact(term, item)
+
+			if def != nil && def.container == "@language" {
+				lang, _ := item.(map[string]interface{})["@language"].(string)
+				langMap, _ := result[term].(map[string]interface{})
+				if langMap == nil {
+					langMap = make(map[string]interface{})
+					result[term] = langMap
+				}
+				if existing, ok := langMap[lang]; ok {
+					langMap[lang] = append(asList(existing), compacted)
+				} else {
+					langMap[lang] = compacted
+				}
+				continue
+			}
+
+			if def != nil && def.container == "@list" {
+				result[term] = compacted
+				continue
+			}
+
+			existing, ok := result[term]
+			switch {
+			case ok:
+				result[term] = append(asList(existing), compacted)
+			case c.isArrayContainer(term):
+				result[term] = []interface{}{compacted}
+			default:
+				result[term] = compacted
+			}
+		}
+	}
+	return result
+}
+
+// compactValue compacts value objects and node references for the given
+// term. It returns false if the element is a full node object.
+func (c *activeContext) compactValue(prop string, element map[string]interface{}) (interface{}, bool) {
+	def := c.terms[prop]
+
+	if id, ok := element["@id"].(string); ok && len(element) == 1 {
+		if def != nil && (def.typ == "@id" || def.typ == "@vocab") {
+			return c.compactIRI(id, def.typ == "@vocab"), true
+		}
+		return map[string]interface{}{c.compactIRI("@id", true): c.compactIRI(id, false)}, true
+	}
+
+	val, ok := element["@value"]
+	if !ok {
+		return nil, false
+	}
+
+	typ, _ := element["@type"].(string)
+	lang, hasLang := element["@language"].(string)
+	switch {
+	case len(typ) > 0 && def != nil && def.typ == typ:
+		return val, true
+	case hasLang && def != nil && def.container == "@language":
+		return val, true
+	case len(typ) == 0 && !hasLang && (def == nil || len(def.typ) == 0) && len(c.language) == 0:
+		return val, true
+	case hasLang && lang == c.language && (def == nil || len(def.typ) == 0):
+		return val, true
+	}
+
+	result := map[string]interface{}{c.compactIRI("@value", true): val}
+	if len(typ) > 0 {
+		result[c.compactIRI("@type", true)] = c.compactIRI(typ, true)
+	}
+	if hasLang {
+		result[c.compactIRI("@language", true)] = lang
+	}
+	return result, true
+}
+
+// selectTerm picks the term that best fits the expanded property and value,
+// taking type coercion and containers into account.
+func (c *activeContext) selectTerm(iri string, value interface{}) string {
+	m, _ := value.(map[string]interface{})
+	_, isValue := m["@value"]
+	_, isList := m["@list"]
+	_, hasLang := m["@language"]
+	typ, _ := m["@type"].(string)
+	_, hasID := m["@id"]
+	isRef := hasID && len(m) == 1
+
+	best := ""
+	fallback := ""
+	for _, term := range c.sorted {
+		def := c.terms[term]
+		if def == nil || def.reverse || def.id != iri {
+			continue
+		}
+
+		var match bool
+		switch {
+		case isList:
+			match = def.container == "@list"
+		case def.container == "@list":
+			match = false
+		case isValue && hasLang:
+			match = def.container == "@language"
+		case def.container == "@language":
+			match = false
+		case isValue && len(typ) > 0:
+			match = def.typ == typ
+		case isValue:
+			match = len(def.typ) == 0
+		case isRef:
+			match = def.typ == "@id" || def.typ == "@vocab"
+		default:
+			match = len(def.typ) == 0 || def.typ == "@id"
+		}
+
+		if match && betterTerm(term, best) {
+			best = term
+		}
+		if !isList && len(def.typ) == 0 && (len(def.container) == 0 || def.container == "@set") && betterTerm(term, fallback) {
+			fallback = term
+		}
+	}
+
+	if len(best) > 0 {
+		return best
+	}
+	if len(fallback) > 0 {
+		return fallback
+	}
+	return c.compactIRI(iri, true)
+}
+
+// compactIRI shortens an IRI to a term, a vocabulary-relative IRI, or a
+// compact IRI like "as:Public".
+func (c *activeContext) compactIRI(iri string, vocab bool) string {
+	if isKeyword(iri) || vocab {
+		best := ""
+		for _, term := range c.sorted {
+			if def := c.terms[term]; def != nil && def.id == iri && !def.reverse && betterTerm(term, best) {
+				best = term
+			}
+		}
+		if len(best) > 0 || isKeyword(iri) {
+			if len(best) == 0 {
+				return iri
+			}
+			return best
+		}
+	}
+
+	if vocab && len(c.vocab) > 0 && strings.HasPrefix(iri, c.vocab) && len(iri) > len(c.vocab) {
+		suffix := iri[len(c.vocab):]
+		if _, ok := c.terms[suffix]; !ok {
+			return suffix
+		}
+	}
+
+	best := ""
+	for _, term := range c.sorted {
+		def := c.terms[term]
+		if def == nil || def.reverse || strings.Contains(term, ":") || isKeyword(def.id) {
+			continue
+		}
+		if def.id == iri || !strings.HasPrefix(iri, def.id) || !endsWithGenDelim(def.id) {
+			continue
+		}
+
+		candidate := term + ":" + iri[len(def.id):]
+		if existing, ok := c.terms[candidate]; ok && (existing == nil || existing.id != iri) {
+			continue
+		}
+		if betterTerm(candidate, best) {
+			best = candidate
+		}
+	}
+	if len(best) > 0 {
+		return best
+	}
+	return iri
+}
+
+func (c *activeContext) isArrayContainer(term string) bool {
+	def := c.terms[term]
+	return def != nil && (def.container == "@list" || def.container == "@set")
+}
+
+func betterTerm(term, best string) bool {
+	if len(best) == 0 {
+		return true
+	}
+	if len(term) != len(best) {
+		return len(term) < len(best)
+	}
+	return term < best
+}
+
+func endsWithGenDelim(iri string) bool {
+	if len(iri) == 0 {
+		return false
+	}
+	switch iri[len(iri)-1] {
+	case ':', '/', '?', '#', '[', ']', '@':
+		return true
+	}
+	return false
+}
+
+func isListObject(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m["@list"]
+	return ok
+}
+
+func asList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+func withoutKey(m map[string]interface{}, key string) map[string]interface{} {
+	if _, ok := m[key]; !ok {
+		return m
+	}
+	result := make(map[string]interface{}, len(m)-1)
+	for k, v := range m {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedTermKeys(m map[string]*termDefinition) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,207 @@
+package apub_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+const mastodonNote = `{
+	"@context": [
+		"https://www.w3.org/ns/activitystreams",
+		{
+			"ostatus": "http://ostatus.org#",
+			"atomUri": "ostatus:atomUri",
+			"sensitive": "as:sensitive",
+			"Hashtag": "as:Hashtag",
+			"toot": "http://joinmastodon.org/ns#",
+			"blurhash": "toot:blurhash"
+		}
+	],
+	"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+	"type": "Note",
+	"published": "2019-06-13T04:46:37Z",
+	"attributedTo": "https://mastodon.gamedev.place/users/bob",
+	"to": ["https://www.w3.org/ns/activitystreams#Public"],
+	"cc": [
+		"https://mastodon.gamedev.place/users/bob/followers",
+		"https://example.com/users/alice"
+	],
+	"sensitive": false,
+	"atomUri": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+	"content": "<p>Content</p>",
+	"contentMap": {
+		"en": "<p>Content EN</p>"
+	},
+	"tag": [{
+		"type": "Hashtag",
+		"href": "https://mastodon.gamedev.place/tags/activitypub",
+		"name": "#activitypub"
+	}],
+	"attachment": [{
+		"type": "Document",
+		"mediaType": "image/png",
+		"url": "https://example.com/image.png",
+		"blurhash": "UEHLh["
+	}]
+}`
+
+func TestExpand(t *testing.T) {
+	obj := Parse(t, mastodonNote)
+	expanded, err := apub.Expand(obj)
+	require.Nil(t, err)
+
+	as := "https://www.w3.org/ns/activitystreams#"
+	assert.Equal(t, "https://mastodon.gamedev.place/users/bob/statuses/4815162342", expanded.Str("@id"))
+	assert.Equal(t, []string{as + "Note"}, expanded.IDs("@type"))
+
+	b, err := json.Marshal(expanded)
+	require.Nil(t, err)
+
+	var data map[string]interface{}
+	require.Nil(t, json.Unmarshal(b, &data))
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"@id": "https://mastodon.gamedev.place/users/bob/followers"},
+		map[string]interface{}{"@id": "https://example.com/users/alice"},
+	}, data[as+"cc"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"@value": "2019-06-13T04:46:37Z", "@type": "http://www.w3.org/2001/XMLSchema#dateTime"},
+	}, data[as+"published"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"@value": "<p>Content</p>"},
+		map[string]interface{}{"@value": "<p>Content EN</p>", "@language": "en"},
+	}, data[as+"content"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"@value": false},
+	}, data[as+"sensitive"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"@value": "https://mastodon.gamedev.place/users/bob/statuses/4815162342"},
+	}, data["http://ostatus.org#atomUri"])
+
+	atts, ok := data[as+"attachment"].([]interface{})
+	require.True(t, ok, data[as+"attachment"])
+	require.Equal(t, 1, len(atts))
+	att := atts[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{as + "Document"}, att["@type"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"@value": "UEHLh["}}, att["http://joinmastodon.org/ns#blurhash"])
+	assert.Nil(t, att["@context"])
+}
+
+func TestCompact(t *testing.T) {
+	obj := Parse(t, mastodonNote)
+	expanded, err := apub.Expand(obj)
+	require.Nil(t, err)
+
+	compacted, err := apub.Compact(expanded, apub.ActivityStreamsContext)
+	require.Nil(t, err)
+
+	assert.Equal(t, apub.ActivityStreamsContext, compacted.Str("@context"))
+	assert.Equal(t, "https://mastodon.gamedev.place/users/bob/statuses/4815162342", compacted.ID())
+	assert.Equal(t, "Note", compacted.Type())
+	assert.Equal(t, "https://mastodon.gamedev.place/users/bob", compacted.Str("attributedTo"))
+	assert.Equal(t, []string{"as:Public"}, compacted.To())
+	assert.Equal(t, []string{
+		"https://mastodon.gamedev.place/users/bob/followers",
+		"https://example.com/users/alice",
+	}, compacted.CC())
+	assert.Equal(t, "2019-06-13T04:46:37Z", compacted.Str("published"))
+	assert.Equal(t, "<p>Content</p>", compacted.Str("content"))
+	assert.Equal(t, "<p>Content EN</p>", compacted.Content("en"))
+	assert.False(t, compacted.Bool("as:sensitive"))
+	assert.Equal(t, "2019-06-13T04:46:37Z", compacted.Str("as:published"))
+	assert.Equal(t, "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+		compacted.Str("http://ostatus.org#atomUri"))
+
+	tags := compacted.Tags()
+	if assert.Equal(t, 1, len(tags)) {
+		assert.Equal(t, "as:Hashtag", tags[0].Type())
+		assert.Equal(t, "https://mastodon.gamedev.place/tags/activitypub", tags[0].Str("href"))
+		assert.Equal(t, "#activitypub", tags[0].Name(""))
+	}
+
+	atts := compacted.Attachments()
+	if assert.Equal(t, 1, len(atts)) {
+		assert.Equal(t, "Document", atts[0].Type())
+		assert.Equal(t, "https://example.com/image.png", atts[0].Str("url"))
+		assert.Equal(t, "UEHLh[", atts[0].Str("http://joinmastodon.org/ns#blurhash"))
+	}
+
+	t.Run("original context", func(t *testing.T) {
+		var ctx interface{}
+		require.Nil(t, json.Unmarshal([]byte(`[
+			"https://www.w3.org/ns/activitystreams",
+			{"sensitive": "as:sensitive", "Hashtag": "as:Hashtag"}
+		]`), &ctx))
+
+		compacted, err := apub.Compact(obj, ctx)
+		require.Nil(t, err)
+		assert.Equal(t, "Hashtag", compacted.Tags()[0].Type())
+		assert.False(t, compacted.Bool("sensitive"))
+	})
+
+	t.Run("ordered items", func(t *testing.T) {
+		obj := Parse(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"type": "OrderedCollection",
+			"orderedItems": ["https://example.com/1", "https://example.com/2"]
+		}`)
+
+		expanded, err := apub.Expand(obj)
+		require.Nil(t, err)
+		items := expanded.List("https://www.w3.org/ns/activitystreams#items")
+		require.Equal(t, 1, len(items))
+		assert.Equal(t, 2, len(items[0].List("@list")))
+
+		compacted, err := apub.Compact(expanded, apub.ActivityStreamsContext)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"https://example.com/1", "https://example.com/2"},
+			compacted.IDs("orderedItems"))
+	})
+}
+
+func TestDocumentLoader(t *testing.T) {
+	input := `{
+		"@context": ["https://www.w3.org/ns/activitystreams", "https://example.com/ctx"],
+		"type": "Note",
+		"custom": "value"
+	}`
+
+	t.Run("default", func(t *testing.T) {
+		_, err := apub.Expand(Parse(t, input))
+		assert.True(t, xerrors.Is(err, apub.ErrContextNotFound), err)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		var loads int
+		loader := apub.DocumentLoaderFunc(func(iri string) (interface{}, error) {
+			if iri == "https://example.com/ctx" {
+				loads++
+				return map[string]interface{}{
+					"@context": map[string]interface{}{
+						"custom": "https://example.com/ns#custom",
+					},
+				}, nil
+			}
+			return apub.DefaultDocumentLoader.LoadDocument(iri)
+		})
+
+		dec := &apub.Parser{ProcessContext: true, Loader: loader}
+		obj, err := dec.Parse(strings.NewReader(input))
+		require.Nil(t, err)
+		assert.Equal(t, "value", obj.Str("https://example.com/ns#custom"))
+
+		expanded, err := apub.Expand(obj)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"https://www.w3.org/ns/activitystreams#Note"}, expanded.IDs("@type"))
+		assert.Equal(t, 1, len(expanded.List("https://example.com/ns#custom")))
+
+		// the root context is only loaded when parsing
+		assert.Equal(t, 1, loads)
+	})
+}
@@ -0,0 +1,189 @@
+package apub
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// PublicKey is a key from an actor's publicKey or assertionMethod properties.
+type PublicKey struct {
+	ID string
+
+	// Owner is the owner of a publicKey, or the controller of a Multikey.
+	Owner string
+
+	// Key is an *rsa.PublicKey or ed25519.PublicKey.
+	Key crypto.PublicKey
+}
+
+const (
+	multicodecEd25519 = 0xed
+	multicodecRSA     = 0x1205
+)
+
+// PublicKeys returns the actor's keys from publicKey, which Mastodon sets to a
+// single object with a PEM key, and from assertionMethod Multikey entries.
+// Every key must be owned by the actor. Keys that can't be decoded are skipped,
+// and the first error is returned with the valid keys.
+func (o *Object) PublicKeys() ([]*PublicKey, error) {
+	var keys []*PublicKey
+	var firstErr error
+	add := func(key *PublicKey, err error) {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		keys = append(keys, key)
+	}
+
+	pubKeys, err := o.FetchList("publicKey")
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range pubKeys {
+		if pem := k.Str("publicKeyPem"); len(pem) > 0 {
+			key, err := decodePEM(pem)
+			add(o.publicKey(k, k.Str("owner"), key, err))
+		}
+	}
+
+	methods, err := o.FetchList("assertionMethod")
+	if err != nil {
+		return keys, err
+	}
+	for _, m := range methods {
+		mb := m.Str("publicKeyMultibase")
+		if len(mb) == 0 {
+			continue
+		}
+		if ty := m.Type(); ty != "Multikey" {
+			add(nil, xerrors.Errorf("PublicKeys: %q has type %q, not Multikey: %w", m.ID(), ty, ErrInvalidKey))
+			continue
+		}
+		key, err := decodeMultikey(mb)
+		add(o.publicKey(m, m.Str("controller"), key, err))
+	}
+
+	return keys, firstErr
+}
+
+// PublicKey returns the actor's key with the given id, such as the keyId of an
+// HTTP signature.
+func (o *Object) PublicKey(id string) (*PublicKey, error) {
+	keys, err := o.PublicKeys()
+	for _, key := range keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, xerrors.Errorf("PublicKey: %q: %w", id, ErrKeyNotFound)
+}
+
+// publicKey checks that the decoded key is owned by the actor.
+func (o *Object) publicKey(k *Object, owner string, key crypto.PublicKey, err error) (*PublicKey, error) {
+	if err != nil {
+		return nil, xerrors.Errorf("PublicKeys: %q: %w", k.ID(), err)
+	}
+	if owner != o.ID() {
+		return nil, xerrors.Errorf("PublicKeys: %q is owned by %q, not %q: %w",
+			k.ID(), owner, o.ID(), ErrKeyOwnerMismatch)
+	}
+	return &PublicKey{ID: k.ID(), Owner: owner, Key: key}, nil
+}
+
+// decodePEM parses an SPKI or PKCS #1 public key. Literal "\n" escapes are
+// replaced with newlines first, since some servers double escape them.
+func decodePEM(s string) (crypto.PublicKey, error) {
+	s = strings.ReplaceAll(s, `\n`, "\n")
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, xerrors.Errorf("decodePEM: no PEM block: %w", ErrInvalidKey)
+	}
+
+	var key crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("decodePEM: %s: %v: %w", block.Type, err, ErrInvalidKey)
+	}
+	return key, nil
+}
+
+// decodeMultikey parses a base58btc encoded multibase key, with an ed25519 or
+// RSA multicodec prefix.
+func decodeMultikey(s string) (crypto.PublicKey, error) {
+	if !strings.HasPrefix(s, "z") {
+		return nil, xerrors.Errorf("decodeMultikey: unsupported multibase %q: %w", s[:1], ErrInvalidKey)
+	}
+
+	data, ok := decodeBase58(s[1:])
+	if !ok {
+		return nil, xerrors.Errorf("decodeMultikey: invalid base58: %w", ErrInvalidKey)
+	}
+
+	codec, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, xerrors.Errorf("decodeMultikey: invalid multicodec: %w", ErrInvalidKey)
+	}
+	data = data[n:]
+
+	switch codec {
+	case multicodecEd25519:
+		if len(data) != ed25519.PublicKeySize {
+			return nil, xerrors.Errorf("decodeMultikey: ed25519 key has %d bytes: %w", len(data), ErrInvalidKey)
+		}
+		return ed25519.PublicKey(data), nil
+	case multicodecRSA:
+		key, err := x509.ParsePKCS1PublicKey(data)
+		if err != nil {
+			return nil, xerrors.Errorf("decodeMultikey: rsa: %v: %w", err, ErrInvalidKey)
+		}
+		return key, nil
+	default:
+		return nil, xerrors.Errorf("decodeMultikey: unsupported multicodec 0x%x: %w", codec, ErrInvalidKey)
+	}
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(s string) ([]byte, bool) {
+	// each base58 digit is log(58)/log(256) bytes, so len(s) bytes is enough.
+	out := make([]byte, len(s))
+	size := 0
+	zeros := 0
+	for ; zeros < len(s) && s[zeros] == '1'; zeros++ {
+	}
+
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, false
+		}
+		for j := 0; j < size; j++ {
+			carry += int(out[len(out)-1-j]) * 58
+			out[len(out)-1-j] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			size++
+			out[len(out)-size] = byte(carry)
+		}
+	}
+	return append(make([]byte, zeros), out[len(out)-size:]...), true
+}
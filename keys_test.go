@@ -0,0 +1,179 @@
+package apub_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// escaped like parse_mastodon_test.go, with literal \n sequences in the
+	// decoded string.
+	mastodonKeyPem = `-----BEGIN PUBLIC KEY-----\\nMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAs+LxLJfjz+6Yf+1nh8rp\\na/ugMbp1geZFm2AsGZyIyB7CP/wRuzO9WmGkRQNpJmgEaYsiPN0l0ZcwoUtkXp41\\nZdUIOjuftLdNZAAaYFXzMEfmN3yE9LG5zOT9B3RSH/93psujPt0xUcurpN4L/III\\nwo9HawigZXPSY5J79Y4kDUOIpdw0o/36h0cZwAhrG+VHfAaHI5hShNW+6VzpWujP\\nzFI7eTtgJYLwE0PyJqLDqInbFINf4JaJqtvk7dLYeCQhPV8FrZMlsmrMVOY4TdAI\\nNKPu6QujEQjvguJy60//XYkH8stu5nlXKUR6GuY4s/Mo1mAb/bXo6lwMITAWPCD0\\noQIDAQAB\\n-----END PUBLIC KEY-----`
+
+	// from the did:key spec
+	ed25519Multikey = "z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2"
+
+	// the PKCS #1 form of mastodonKeyPem
+	rsaMultikey = "z4MXj1wBzi9jUstyPQLWc4UBXzAaECuMSqKXoZ9J47qqqjZK92aYZUxvP1297nmNNbCXKh7cZUVA9bxwd8TqeTXGZKHYhTSwMFZ6oVMifQHdrnZrytpBd3jNd5htpSyRuzo33UbWXMBxbz4NMu9eHED2sQZzZU4n5juaseYHHKLT1NdaXi6o7f5K8ZFy6eEPV1nkiUUDgEzghwU2jApp1yKoi4kFrKnsR3DeG3qCNtvGBjFfiw3tWnrivtX261qDhgjzF9Q9ifWa1BZeCPBJKF1GHvL1w7nfUDCkURhU4EU4MrvcFdiHt7LnKKx9E6Zaz8r98g3xr2fF5S3CqXuLar2PrDiat6TDehwZAJJiWs8T2e9nMz1FE"
+)
+
+func TestPublicKeys(t *testing.T) {
+	t.Run("mastodon", func(t *testing.T) {
+		obj := Parse(t, `{
+			"id": "https://mastodon.gamedev.place/users/bob",
+			"type": "Person",
+			"publicKey": {
+				"id": "https://mastodon.gamedev.place/users/bob#main-key",
+				"owner": "https://mastodon.gamedev.place/users/bob",
+				"publicKeyPem": "`+mastodonKeyPem+`"
+			}
+		}`)
+
+		keys, err := obj.PublicKeys()
+		require.Nil(t, err)
+		require.Equal(t, 1, len(keys))
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob#main-key", keys[0].ID)
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob", keys[0].Owner)
+		if rsaKey, ok := keys[0].Key.(*rsa.PublicKey); assert.True(t, ok) {
+			assert.Equal(t, 2048, rsaKey.N.BitLen())
+		}
+
+		key, err := obj.PublicKey("https://mastodon.gamedev.place/users/bob#main-key")
+		require.Nil(t, err)
+		assert.Equal(t, keys[0], key)
+
+		_, err = obj.PublicKey("https://mastodon.gamedev.place/users/bob#other-key")
+		assert.True(t, xerrors.Is(err, apub.ErrKeyNotFound), err)
+	})
+
+	t.Run("multiple keys", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.Nil(t, err)
+		pkcs1, err := json.Marshal(string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PUBLIC KEY",
+			Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey),
+		})))
+		require.Nil(t, err)
+
+		obj := Parse(t, fmt.Sprintf(`{
+			"id": "https://example.com/~erik",
+			"type": "Person",
+			"publicKey": [
+				{
+					"id": "https://example.com/~erik#main-key",
+					"owner": "https://example.com/~erik",
+					"publicKeyPem": "%s"
+				},
+				{
+					"id": "https://example.com/~erik#pkcs1",
+					"owner": "https://example.com/~erik",
+					"publicKeyPem": %s
+				}
+			],
+			"assertionMethod": [
+				"https://example.com/~erik#main-key",
+				{
+					"id": "https://example.com/~erik#ed25519-key",
+					"type": "Multikey",
+					"controller": "https://example.com/~erik",
+					"publicKeyMultibase": "%s"
+				},
+				{
+					"id": "https://example.com/~erik#rsa-key",
+					"type": "Multikey",
+					"controller": "https://example.com/~erik",
+					"publicKeyMultibase": "%s"
+				}
+			]
+		}`, mastodonKeyPem, pkcs1, ed25519Multikey, rsaMultikey))
+
+		keys, err := obj.PublicKeys()
+		require.Nil(t, err)
+		require.Equal(t, 4, len(keys))
+
+		assert.Equal(t, "https://example.com/~erik#pkcs1", keys[1].ID)
+		assert.True(t, rsaKey.PublicKey.Equal(keys[1].Key))
+
+		assert.Equal(t, "https://example.com/~erik#ed25519-key", keys[2].ID)
+		assert.Equal(t, "https://example.com/~erik", keys[2].Owner)
+		if edKey, ok := keys[2].Key.(ed25519.PublicKey); assert.True(t, ok) {
+			assert.Equal(t, ed25519.PublicKeySize, len(edKey))
+		}
+
+		assert.Equal(t, "https://example.com/~erik#rsa-key", keys[3].ID)
+		assert.True(t, keys[0].Key.(*rsa.PublicKey).Equal(keys[3].Key))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		obj := Parse(t, `{
+			"id": "https://example.com/~erik",
+			"type": "Person",
+			"publicKey": [
+				{
+					"id": "https://evil.example/~erik#main-key",
+					"owner": "https://evil.example/~erik",
+					"publicKeyPem": "`+mastodonKeyPem+`"
+				},
+				{
+					"id": "https://example.com/~erik#main-key",
+					"owner": "https://example.com/~erik",
+					"publicKeyPem": "`+mastodonKeyPem+`"
+				},
+				{
+					"id": "https://example.com/~erik#bad-key",
+					"owner": "https://example.com/~erik",
+					"publicKeyPem": "nope"
+				}
+			],
+			"assertionMethod": {
+				"id": "https://example.com/~erik#secp256k1",
+				"type": "Multikey",
+				"controller": "https://example.com/~erik",
+				"publicKeyMultibase": "zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme"
+			}
+		}`)
+
+		keys, err := obj.PublicKeys()
+		assert.True(t, xerrors.Is(err, apub.ErrKeyOwnerMismatch), err)
+		if assert.Equal(t, 1, len(keys)) {
+			assert.Equal(t, "https://example.com/~erik#main-key", keys[0].ID)
+		}
+
+		key, err := obj.PublicKey("https://example.com/~erik#main-key")
+		assert.Nil(t, err)
+		assert.Equal(t, keys[0], key)
+
+		_, err = obj.PublicKey("https://example.com/~erik#bad-key")
+		assert.True(t, xerrors.Is(err, apub.ErrKeyOwnerMismatch), err)
+
+		obj.Del("publicKey")
+		keys, err = obj.PublicKeys()
+		assert.True(t, xerrors.Is(err, apub.ErrInvalidKey), err)
+		assert.Equal(t, 0, len(keys))
+
+		obj = Parse(t, `{
+			"id": "https://example.com/~erik",
+			"type": "Person",
+			"assertionMethod": {
+				"id": "https://example.com/~erik#ed25519",
+				"type": "Ed25519VerificationKey2020",
+				"controller": "https://example.com/~erik",
+				"publicKeyMultibase": "`+ed25519Multikey+`"
+			}
+		}`)
+		keys, err = obj.PublicKeys()
+		assert.True(t, xerrors.Is(err, apub.ErrInvalidKey), err)
+		assert.Equal(t, 0, len(keys))
+	})
+}
@@ -0,0 +1,268 @@
+package apub
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	NodeInfo20Schema = "http://nodeinfo.diaspora.software/ns/schema/2.0"
+	NodeInfo21Schema = "http://nodeinfo.diaspora.software/ns/schema/2.1"
+)
+
+// NodeInfo describes a server, as described by the NodeInfo 2.0 and 2.1
+// schemas.
+type NodeInfo struct {
+	Version           string                 `json:"version"`
+	Software          NodeInfoSoftware       `json:"software"`
+	Protocols         []string               `json:"protocols"`
+	Services          NodeInfoServices       `json:"services"`
+	OpenRegistrations bool                   `json:"openRegistrations"`
+	Usage             NodeInfoUsage          `json:"usage"`
+	Metadata          map[string]interface{} `json:"metadata"`
+}
+
+type NodeInfoSoftware struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Repository and Homepage are new in NodeInfo 2.1.
+	Repository string `json:"repository,omitempty"`
+	Homepage   string `json:"homepage,omitempty"`
+}
+
+type NodeInfoServices struct {
+	Inbound  []string `json:"inbound"`
+	Outbound []string `json:"outbound"`
+}
+
+type NodeInfoUsage struct {
+	Users         NodeInfoUsers `json:"users"`
+	LocalPosts    int           `json:"localPosts,omitempty"`
+	LocalComments int           `json:"localComments,omitempty"`
+}
+
+type NodeInfoUsers struct {
+	Total          int `json:"total,omitempty"`
+	ActiveHalfyear int `json:"activeHalfyear,omitempty"`
+	ActiveMonth    int `json:"activeMonth,omitempty"`
+}
+
+// SupportsProtocol returns true if the server lists the protocol, such as
+// "activitypub".
+func (n *NodeInfo) SupportsProtocol(protocol string) bool {
+	for _, p := range n.Protocols {
+		if strings.EqualFold(p, protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseNodeInfo decodes a NodeInfo document with the Object accessors, so
+// that values of the wrong type, such as a numeric string for usage counts,
+// are tolerated. Values that can't be decoded are left empty, and the first
+// error is returned with the document. Only a missing software name fails
+// outright.
+func ParseNodeInfo(r io.Reader) (*NodeInfo, error) {
+	data := make(map[string]interface{})
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, xerrors.Errorf("ParseNodeInfo: %v: %w", err, ErrInvalidNodeInfo)
+	}
+
+	obj := New(data)
+	software := obj.Object("software")
+	services := obj.Object("services")
+	usage := obj.Object("usage")
+	users := usage.Object("users")
+
+	info := &NodeInfo{
+		Version: obj.Str("version"),
+		Software: NodeInfoSoftware{
+			Name:       strings.ToLower(software.Str("name")),
+			Version:    software.Str("version"),
+			Repository: software.Str("repository"),
+			Homepage:   software.Str("homepage"),
+		},
+		Protocols: obj.IDs("protocols"),
+		Services: NodeInfoServices{
+			Inbound:  services.IDs("inbound"),
+			Outbound: services.IDs("outbound"),
+		},
+		OpenRegistrations: obj.Bool("openRegistrations"),
+		Usage: NodeInfoUsage{
+			Users: NodeInfoUsers{
+				Total:          users.Int("total"),
+				ActiveHalfyear: users.Int("activeHalfyear"),
+				ActiveMonth:    users.Int("activeMonth"),
+			},
+			LocalPosts:    usage.Int("localPosts"),
+			LocalComments: usage.Int("localComments"),
+		},
+	}
+	if metadata, ok := data["metadata"].(map[string]interface{}); ok {
+		info.Metadata = metadata
+	}
+
+	if len(info.Software.Name) == 0 {
+		return info, xerrors.Errorf("ParseNodeInfo: no software name: %w", ErrInvalidNodeInfo)
+	}
+	if errs := obj.Errors(); len(errs) > 0 {
+		return info, errs[0]
+	}
+	return info, nil
+}
+
+// NodeInfoHandler serves the /.well-known/nodeinfo discovery document, and
+// the NodeInfo 2.0 and 2.1 documents it links to at /nodeinfo/2.0 and
+// /nodeinfo/2.1.
+type NodeInfoHandler struct {
+	// BaseURL is the URL of the server, such as "https://example.com".
+	BaseURL string
+
+	// NodeInfo returns the current document. Its Version is set to the
+	// requested version.
+	NodeInfo func(ctx context.Context) (*NodeInfo, error)
+}
+
+func (h *NodeInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	base := strings.TrimSuffix(h.BaseURL, "/")
+	switch r.URL.Path {
+	case "/.well-known/nodeinfo":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"links": []map[string]string{
+				{"rel": NodeInfo21Schema, "href": base + "/nodeinfo/2.1"},
+				{"rel": NodeInfo20Schema, "href": base + "/nodeinfo/2.0"},
+			},
+		})
+	case "/nodeinfo/2.0", "/nodeinfo/2.1":
+		info, err := h.NodeInfo(r.Context())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		doc := *info
+		doc.Version = r.URL.Path[len("/nodeinfo/"):]
+		if doc.Version == "2.0" {
+			doc.Software.Repository = ""
+			doc.Software.Homepage = ""
+		}
+		if doc.Protocols == nil {
+			doc.Protocols = []string{}
+		}
+		if doc.Services.Inbound == nil {
+			doc.Services.Inbound = []string{}
+		}
+		if doc.Services.Outbound == nil {
+			doc.Services.Outbound = []string{}
+		}
+		if doc.Metadata == nil {
+			doc.Metadata = map[string]interface{}{}
+		}
+
+		w.Header().Set("Content-Type", `application/json; profile="http://nodeinfo.diaspora.software/ns/schema/`+doc.Version+`#"`)
+		json.NewEncoder(w).Encode(&doc)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// NodeInfoClient fetches the NodeInfo of other servers.
+type NodeInfoClient struct {
+	// Client sends the requests. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// MaxBodySize is the largest accepted document. DefaultMaxBodySize is used
+	// if zero.
+	MaxBodySize int64
+}
+
+// Fetch discovers the NodeInfo of the host, preferring version 2.1.
+func (c *NodeInfoClient) Fetch(ctx context.Context, host string) (*NodeInfo, error) {
+	u := &url.URL{Scheme: "https", Host: host, Path: "/.well-known/nodeinfo"}
+	res, err := c.get(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var discovery struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, c.maxBodySize())).Decode(&discovery); err != nil {
+		return nil, xerrors.Errorf("Fetch: %s: %v: %w", u, err, ErrInvalidNodeInfo)
+	}
+
+	var href string
+	for _, schema := range []string{NodeInfo21Schema, NodeInfo20Schema} {
+		for _, link := range discovery.Links {
+			if strings.TrimSuffix(link.Rel, "#") == schema && len(link.Href) > 0 {
+				href = link.Href
+				break
+			}
+		}
+		if len(href) > 0 {
+			break
+		}
+	}
+	if len(href) == 0 {
+		return nil, xerrors.Errorf("Fetch: %s: no NodeInfo 2.x link: %w", u, ErrInvalidNodeInfo)
+	}
+
+	res, err = c.get(ctx, href)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return ParseNodeInfo(io.LimitReader(res.Body, c.maxBodySize()))
+}
+
+func (c *NodeInfoClient) get(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("Fetch: %s: %v: %w", u, err, ErrFetchFailed)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("Fetch: %s: %v: %w", u, err, ErrFetchFailed)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, xerrors.Errorf("Fetch: %s: %s: %w", u, res.Status, ErrFetchFailed)
+	}
+	return res, nil
+}
+
+func (c *NodeInfoClient) maxBodySize() int64 {
+	if c.MaxBodySize > 0 {
+		return c.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
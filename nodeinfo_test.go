@@ -0,0 +1,135 @@
+package apub_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestNodeInfo(t *testing.T) {
+	var base string
+	handler := &apub.NodeInfoHandler{
+		NodeInfo: func(ctx context.Context) (*apub.NodeInfo, error) {
+			return &apub.NodeInfo{
+				Software: apub.NodeInfoSoftware{
+					Name:       "apub",
+					Version:    "0.1.0",
+					Repository: "https://github.com/technoweenie/apub",
+				},
+				Protocols:         []string{"activitypub"},
+				OpenRegistrations: true,
+				Usage: apub.NodeInfoUsage{
+					Users:      apub.NodeInfoUsers{Total: 3, ActiveMonth: 1},
+					LocalPosts: 42,
+				},
+			}, nil
+		},
+	}
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	base = server.URL
+	handler.BaseURL = base + "/"
+
+	get := func(t *testing.T, path string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest("GET", path, nil))
+		return res
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		res := get(t, "/.well-known/nodeinfo")
+		assert.Equal(t, http.StatusOK, res.Code)
+		var discovery map[string][]map[string]string
+		require.Nil(t, json.NewDecoder(res.Body).Decode(&discovery))
+		assert.Equal(t, []map[string]string{
+			{"rel": apub.NodeInfo21Schema, "href": base + "/nodeinfo/2.1"},
+			{"rel": apub.NodeInfo20Schema, "href": base + "/nodeinfo/2.0"},
+		}, discovery["links"])
+
+		res = get(t, "/nodeinfo/2.1")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `application/json; profile="http://nodeinfo.diaspora.software/ns/schema/2.1#"`, res.Header().Get("Content-Type"))
+		body := res.Body.String()
+		assert.True(t, strings.Contains(body, `"version":"2.1"`), body)
+		assert.True(t, strings.Contains(body, `"repository":"https://github.com/technoweenie/apub"`), body)
+		assert.True(t, strings.Contains(body, `"services":{"inbound":[],"outbound":[]}`), body)
+		assert.True(t, strings.Contains(body, `"metadata":{}`), body)
+
+		res = get(t, "/nodeinfo/2.0")
+		body = res.Body.String()
+		assert.True(t, strings.Contains(body, `"version":"2.0"`), body)
+		assert.False(t, strings.Contains(body, `repository`), body)
+
+		assert.Equal(t, http.StatusNotFound, get(t, "/nodeinfo/1.0").Code)
+	})
+
+	t.Run("client", func(t *testing.T) {
+		u, err := url.Parse(server.URL)
+		require.Nil(t, err)
+
+		client := &apub.NodeInfoClient{Client: server.Client()}
+		info, err := client.Fetch(context.Background(), u.Host)
+		require.Nil(t, err)
+		assert.Equal(t, "2.1", info.Version)
+		assert.Equal(t, "apub", info.Software.Name)
+		assert.Equal(t, "0.1.0", info.Software.Version)
+		assert.Equal(t, "https://github.com/technoweenie/apub", info.Software.Repository)
+		assert.True(t, info.SupportsProtocol("ActivityPub"))
+		assert.False(t, info.SupportsProtocol("diaspora"))
+		assert.True(t, info.OpenRegistrations)
+		assert.Equal(t, 3, info.Usage.Users.Total)
+		assert.Equal(t, 42, info.Usage.LocalPosts)
+
+		_, err = client.Fetch(context.Background(), "127.0.0.1:1")
+		assert.True(t, xerrors.Is(err, apub.ErrFetchFailed), err)
+	})
+}
+
+func TestParseNodeInfo(t *testing.T) {
+	t.Run("tolerant", func(t *testing.T) {
+		info, err := apub.ParseNodeInfo(strings.NewReader(`{
+			"version": "2.0",
+			"software": {"name": "Mastodon", "version": "4.2.0"},
+			"protocols": "activitypub",
+			"openRegistrations": "true",
+			"usage": {"users": {"total": "12", "activeMonth": 3.0}},
+			"metadata": {"nodeName": "gamedev.place"}
+		}`))
+		require.Nil(t, err)
+		assert.Equal(t, "mastodon", info.Software.Name)
+		assert.Equal(t, []string{"activitypub"}, info.Protocols)
+		assert.True(t, info.OpenRegistrations)
+		assert.Equal(t, 12, info.Usage.Users.Total)
+		assert.Equal(t, 3, info.Usage.Users.ActiveMonth)
+		assert.Equal(t, "gamedev.place", info.Metadata["nodeName"])
+	})
+
+	t.Run("bad values", func(t *testing.T) {
+		info, err := apub.ParseNodeInfo(strings.NewReader(`{
+			"version": "2.0",
+			"software": {"name": "pixelfed", "version": "0.11"},
+			"protocols": ["activitypub"],
+			"usage": {"users": {"total": "lots"}}
+		}`))
+		assert.True(t, xerrors.Is(err, apub.ErrInvalidInt), err)
+		assert.Equal(t, "pixelfed", info.Software.Name)
+		assert.Equal(t, 0, info.Usage.Users.Total)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := apub.ParseNodeInfo(strings.NewReader(`{"version": "2.0"}`))
+		assert.True(t, xerrors.Is(err, apub.ErrInvalidNodeInfo), err)
+
+		_, err = apub.ParseNodeInfo(strings.NewReader(`[]`))
+		assert.True(t, xerrors.Is(err, apub.ErrInvalidNodeInfo), err)
+	})
+}
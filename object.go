@@ -16,6 +16,11 @@ type Object struct {
 	path         []string
 	lang         string
 	data         map[string]interface{}
+	ctx          *activeContext
+	iris         map[string]string
+	fetcher      Fetcher
+	depth        int
+	ref          bool
 	errors       []error
 	nonFatal     []error
 	addError     func(error)
@@ -23,24 +28,29 @@ type Object struct {
 }
 
 func New(data map[string]interface{}) *Object {
-	obj := &Object{lang: DefaultLang, data: data}
-	if ty := obj.Type(); len(ty) > 0 {
-		obj.path = []string{ty}
-	} else {
-		obj.path = []string{"UnknownType"}
-	}
+	obj := &Object{}
+	obj.reset(data)
+	return obj
+}
 
-	obj.addError = func(err error) {
-		obj.errors = append(obj.errors, err)
+func (o *Object) reset(data map[string]interface{}) {
+	*o = Object{lang: DefaultLang, data: data}
+	o.addError = func(err error) {
+		o.errors = append(o.errors, err)
 	}
-	obj.addLangError = func(err error) {
+	o.addLangError = func(err error) {
 		if FatalLangErr(err) {
-			obj.errors = append(obj.errors, err)
+			o.errors = append(o.errors, err)
 			return
 		}
-		obj.nonFatal = append(obj.nonFatal, err)
+		o.nonFatal = append(o.nonFatal, err)
+	}
+
+	if ty := o.Type(); len(ty) > 0 {
+		o.path = []string{ty}
+	} else {
+		o.path = []string{"UnknownType"}
 	}
-	return obj
 }
 
 func (o *Object) ID() string {
@@ -104,7 +114,7 @@ func (o *Object) Str(key string) string {
 }
 
 func (o *Object) Fetch(key string) (string, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return "", nil
 	}
@@ -128,7 +138,7 @@ func (o *Object) Fetch(key string) (string, error) {
 		if len(objs) == 0 {
 			return "", nil
 		}
-		return objs[0].DefaultValue(), nil
+		return objs[0].DefaultValue(), o.checkFunctional("Fetch", key, len(objs))
 	default:
 		return fmt.Sprintf("%v", ival), nil
 	}
@@ -143,7 +153,7 @@ func (o *Object) Int(key string) int {
 }
 
 func (o *Object) FetchInt(key string) (int, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return 0, nil
 	}
@@ -171,7 +181,7 @@ func (o *Object) Float(key string) float64 {
 }
 
 func (o *Object) FetchFloat(key string) (float64, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return 0, nil
 	}
@@ -199,7 +209,7 @@ func (o *Object) Bool(key string) bool {
 }
 
 func (o *Object) FetchBool(key string) (bool, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return false, nil
 	}
@@ -232,7 +242,7 @@ func (o *Object) Time(key string) time.Time {
 }
 
 func (o *Object) FetchTime(key string) (time.Time, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		var t time.Time
 		return t, nil
@@ -263,7 +273,7 @@ func (o *Object) Object(key string) *Object {
 }
 
 func (o *Object) FetchObject(key string) (*Object, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return nil, nil
 	}
@@ -276,7 +286,7 @@ func (o *Object) FetchObject(key string) (*Object, error) {
 		if len(objs) == 0 {
 			return nil, nil
 		}
-		return objs[0], nil
+		return objs[0], o.checkFunctional("FetchObject", key, len(objs))
 	}
 
 	return o.valueAsObject(key, ival)
@@ -291,7 +301,7 @@ func (o *Object) List(key string) []*Object {
 }
 
 func (o *Object) FetchList(key string) ([]*Object, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return nil, nil
 	}
@@ -375,7 +385,7 @@ func (o *Object) IDs(key string) []string {
 }
 
 func (o *Object) FetchIDs(key string) ([]string, error) {
-	ival, ok := o.data[key]
+	key, ival, ok := o.lookup(key)
 	if !ok {
 		return nil, nil
 	}
@@ -417,16 +427,20 @@ func (o *Object) FetchIDs(key string) ([]string, error) {
 }
 
 func (o *Object) Del(key string) {
+	key, _, _ = o.lookup(key)
 	delete(o.data, key)
+	if o.ctx != nil {
+		o.setContext(o.ctx)
+	}
 }
 
 func (o *Object) SetBool(key string, value bool) error {
-	o.data[key] = value
+	o.set(key, value)
 	return nil
 }
 
 func (o *Object) SetList(key string, value []interface{}) error {
-	o.data[key] = value
+	o.set(key, value)
 	return nil
 }
 
@@ -436,12 +450,12 @@ func (o *Object) AppendList(key string, values ...interface{}) error {
 		return xerrors.Errorf("AppendList: %q: %w", key, ErrInvalidList)
 	}
 
-	o.data[key] = append(list, values...)
+	o.set(key, append(list, values...))
 	return nil
 }
 
 func (o *Object) SetNum(key string, value float64) error {
-	o.data[key] = value
+	o.set(key, value)
 	return nil
 }
 
@@ -449,7 +463,7 @@ func (o *Object) SetObject(key string, value map[string]interface{}) error {
 	for k, v := range value {
 		switch v.(type) {
 		case bool, string, float64, []interface{}, map[string]interface{}:
-			o.data[key] = value
+			o.set(key, value)
 		}
 		return xerrors.Errorf("SetObject: %s.%s = %+v: %w",
 			key, k, v, ErrKeyTypeNotObject)
@@ -458,7 +472,7 @@ func (o *Object) SetObject(key string, value map[string]interface{}) error {
 }
 
 func (o *Object) SetStr(key string, value string) error {
-	o.data[key] = value
+	o.set(key, value)
 	return nil
 }
 
@@ -475,30 +489,23 @@ func (o *Object) valueAsObject(key string, ival interface{}) (*Object, error) {
 	case map[string]interface{}:
 		return o.newObj(key, val), nil
 	case string:
-		otype := o.Type()
-		var ptypes map[string]string
-
-		if pt, ok := propertyTypes[otype]; ok {
-			ptypes = pt
-		} else {
-			ptypes = propertyTypes["Object"] // always exists
-		}
-
-		keyType, ok := ptypes[key]
-		if !ok {
+		prop, ok := o.property(key)
+		if !ok || len(prop.iriType) == 0 {
 			return o.newObj(key, map[string]interface{}{
 				"id": val,
 			}), nil
 		}
 
-		defkey := defaults[keyType]
+		defkey := defaultKey(prop.iriType)
 		if len(defkey) == 0 {
 			defkey = "id"
 		}
-		return o.newObj(key, map[string]interface{}{
-			"type": keyType,
+		obj := o.newObj(key, map[string]interface{}{
+			"type": prop.iriType,
 			defkey: val,
-		}), nil
+		})
+		obj.ref = true
+		return obj, nil
 	default:
 		return nil, xerrors.Errorf("valueAsObject: (%s) %s key %q: (%T) %+v: %w",
 			o.Type(), strings.Join(o.path, "."), key, ival, ival, ErrKeyTypeNotObject)
@@ -506,13 +513,26 @@ func (o *Object) valueAsObject(key string, ival interface{}) (*Object, error) {
 }
 
 func (o *Object) DefaultValue() string {
-	defkey, ok := defaults[o.Type()]
-	if !ok {
+	defkey := defaultKey(o.Type())
+	if len(defkey) == 0 {
 		return o.ID()
 	}
 	return o.Str(defkey)
 }
 
+// checkFunctional returns an error if a functional property, such as
+// "published" or "inbox", has more than one value.
+func (o *Object) checkFunctional(fn, key string, n int) error {
+	if n < 2 || !vocabProperties[key].functional {
+		return nil
+	}
+	if _, ok := o.property(key); !ok {
+		return nil
+	}
+	return xerrors.Errorf("%s: %s.%s has %d values: %w",
+		fn, strings.Join(o.path, "."), key, n, ErrFunctionalList)
+}
+
 func (o *Object) valueAsList(key string, list []interface{}) ([]*Object, error) {
 	objs := make([]*Object, 0, len(list))
 	for _, iv := range list {
@@ -526,25 +546,92 @@ func (o *Object) valueAsList(key string, list []interface{}) ([]*Object, error)
 }
 
 func (o *Object) newObj(key string, data map[string]interface{}) *Object {
-	return &Object{
+	obj := &Object{
 		path:         append(o.path, key),
 		lang:         o.lang,
 		data:         data,
+		ctx:          o.ctx,
+		fetcher:      o.fetcher,
+		depth:        o.depth,
 		addError:     o.addError,
 		addLangError: o.addLangError,
 	}
+
+	if local, ok := data["@context"]; ok && o.ctx != nil {
+		ctx, err := o.ctx.parse(local)
+		if err != nil {
+			o.addError(err)
+		}
+		obj.ctx = ctx
+	}
+	if obj.ctx != nil {
+		obj.setContext(obj.ctx)
+	}
+	return obj
+}
+
+// lookup returns the key and value of the given property. If the object was
+// parsed with its JSON-LD context, the property may also be given as a compact
+// or absolute IRI, such as "toot:featured" or
+// "http://joinmastodon.org/ns#featured".
+func (o *Object) lookup(key string) (string, interface{}, bool) {
+	if ival, ok := o.data[key]; ok {
+		return key, ival, true
+	}
+	if o.ctx == nil {
+		return key, nil, false
+	}
+
+	iri := o.ctx.expandTerm(key)
+	if k, ok := o.iris[iri]; ok && len(iri) > 0 {
+		return k, o.data[k], true
+	}
+	return key, nil, false
+}
+
+// setContext sets the JSON-LD context of the object, and indexes its keys by
+// their absolute IRIs for lookup.
+func (o *Object) setContext(ctx *activeContext) {
+	o.ctx = ctx
+	o.iris = nil
+	if ctx == nil {
+		return
+	}
+
+	o.iris = make(map[string]string, len(o.data))
+	for k := range o.data {
+		o.indexKey(k)
+	}
+}
+
+// indexKey adds a key to the IRI index. If several keys expand to the same
+// IRI, keys without a language map are preferred, then the first key in
+// sorted order.
+func (o *Object) indexKey(k string) {
+	if o.iris == nil {
+		return
+	}
+	iri := o.ctx.expandTerm(k)
+	if len(iri) == 0 {
+		return
+	}
+	if prev, ok := o.iris[iri]; ok {
+		if o.isLangMap(prev) == o.isLangMap(k) && prev < k {
+			return
+		}
+		if !o.isLangMap(prev) && o.isLangMap(k) {
+			return
+		}
+	}
+	o.iris[iri] = k
 }
 
-var propertyTypes = map[string]map[string]string{
-	"Object": map[string]string{
-		"icon":  "Image",
-		"image": "Image",
-		"url":   "Link",
-	},
+func (o *Object) isLangMap(k string) bool {
+	def := o.ctx.terms[k]
+	return def != nil && def.container == "@language"
 }
 
-var defaults = map[string]string{
-	"Image":   "url",
-	"Link":    "href",
-	"Mention": "href",
+func (o *Object) set(key string, value interface{}) {
+	o.data[key] = value
+	o.indexKey(key)
 }
@@ -0,0 +1,211 @@
+package apub
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// OriginError is returned by an OriginChecker when an id in an activity is not
+// from the same origin as the activity's actor.
+type OriginError struct {
+	// Path is the property with the id, such as "id", "object.attributedTo",
+	// or "keyId" for the key that signed the request.
+	Path string
+
+	// ID is the id from a different origin than Actor.
+	ID    string
+	Actor string
+
+	// Err is ErrOriginMismatch or ErrSignerMismatch.
+	Err error
+}
+
+func (e *OriginError) Error() string {
+	return fmt.Sprintf("%s %q does not match actor %q: %v", e.Path, e.ID, e.Actor, e.Err)
+}
+
+func (e *OriginError) Unwrap() error {
+	return e.Err
+}
+
+// OriginChecker checks that an incoming activity could have come from its
+// actor. The key that signed the request, the activity's id, and the id and
+// attributedTo of an embedded object must share the actor's origin.
+type OriginChecker struct {
+	// Fetcher loads the actor that owns the signing key, and re-fetches
+	// embedded objects from another origin, such as the Note in an Announce.
+	// Without it, the signing key is only checked by origin, and embedded
+	// objects from another origin are rejected. The signing key must share
+	// the actor's origin either way.
+	Fetcher Fetcher
+}
+
+// Check returns an OriginError if the activity fails a same-origin check.
+//
+// The signing key is read from the context with SignerKeyID. If it is missing,
+// such as in an Inbox without a Verifier, the signer is not checked at all, and
+// any actor can be claimed from the actor's origin.
+//
+// The object of a Delete, Update, or Undo must share the actor's origin, even
+// if it is only an IRI. Other objects from another origin, and object IRIs if
+// there is a Fetcher, are replaced with the object fetched from their id, so
+// that handlers only see authentic copies.
+func (c *OriginChecker) Check(ctx context.Context, act *Object) error {
+	actors, err := act.FetchIDs("actor")
+	if err != nil {
+		return err
+	}
+	if len(actors) == 0 {
+		return xerrors.Errorf("Check: %w", ErrMissingActor)
+	}
+	actor := actors[0]
+	actorOrigin := origin(actor)
+	if len(actorOrigin) == 0 {
+		return xerrors.Errorf("Check: actor %q: %w", actor, ErrInvalidIRI)
+	}
+
+	if err := c.checkSigner(ctx, actor); err != nil {
+		return err
+	}
+
+	for _, other := range actors[1:] {
+		if origin(other) != actorOrigin {
+			return &OriginError{Path: "actor", ID: other, Actor: actor, Err: ErrOriginMismatch}
+		}
+	}
+	if id := act.ID(); len(id) > 0 && origin(id) != actorOrigin {
+		return &OriginError{Path: "id", ID: id, Actor: actor, Err: ErrOriginMismatch}
+	}
+
+	obj, err := act.FetchObject("object")
+	if err != nil || obj == nil {
+		return err
+	}
+
+	switch act.Type() {
+	case "Delete", "Update", "Undo":
+		// the actor can only change its own objects, even if they are
+		// authentic.
+		return checkObjectOrigin(obj, actor, actorOrigin)
+	}
+
+	id := obj.ID()
+	if isReference(obj) {
+		if c.Fetcher != nil && len(id) > 0 {
+			if obj, err = c.refetch(ctx, act, id, actor); err != nil {
+				return err
+			}
+		}
+	} else if oerr := checkObjectOrigin(obj, actor, actorOrigin); oerr != nil {
+		if c.Fetcher == nil || len(id) == 0 || origin(id) == actorOrigin {
+			return oerr
+		}
+		if obj, err = c.refetch(ctx, act, id, actor); err != nil {
+			return err
+		}
+	}
+
+	// the actor can only create its own objects.
+	if act.Type() == "Create" {
+		return checkObjectOrigin(obj, actor, actorOrigin)
+	}
+	return nil
+}
+
+// refetch replaces the activity's object with the object fetched from its id.
+func (c *OriginChecker) refetch(ctx context.Context, act *Object, id, actor string) (*Object, error) {
+	fetched, err := c.Fetcher.Fetch(ctx, id)
+	if err != nil {
+		return nil, xerrors.Errorf("Check: %q: %w", id, err)
+	}
+	if fetched.ID() != id {
+		return nil, &OriginError{Path: "object.id", ID: fetched.ID(), Actor: actor, Err: ErrOriginMismatch}
+	}
+	if err := checkObjectOrigin(fetched, id, origin(id)); err != nil {
+		return nil, err
+	}
+	act.data["object"] = embedObject(fetched)
+	return fetched, nil
+}
+
+// checkSigner checks that the key that signed the request shares the actor's
+// origin, and with a Fetcher, that it belongs to the actor. The keyId may
+// return the actor, as Mastodon does, or the key with the actor as its owner,
+// as GoToSocial does.
+func (c *OriginChecker) checkSigner(ctx context.Context, actor string) error {
+	keyID := SignerKeyID(ctx)
+	if len(keyID) == 0 {
+		return nil
+	}
+
+	if origin(keyID) != origin(actor) {
+		return &OriginError{Path: "keyId", ID: keyID, Actor: actor, Err: ErrSignerMismatch}
+	}
+	if c.Fetcher == nil {
+		return nil
+	}
+
+	owner, err := c.Fetcher.Fetch(ctx, keyID)
+	if err != nil {
+		return xerrors.Errorf("Check: keyId %q: %w", keyID, err)
+	}
+	if id := owner.ID(); id == keyID && id != actor {
+		if o := owner.Str("owner"); o != actor {
+			return &OriginError{Path: "keyId", ID: keyID, Actor: actor, Err: ErrSignerMismatch}
+		}
+		if owner, err = c.Fetcher.Fetch(ctx, actor); err != nil {
+			return xerrors.Errorf("Check: actor %q: %w", actor, err)
+		}
+	}
+	if _, err := owner.PublicKey(keyID); err != nil {
+		return xerrors.Errorf("Check: %w", err)
+	}
+	if owner.ID() != actor {
+		return &OriginError{Path: "keyId", ID: keyID, Actor: actor, Err: ErrSignerMismatch}
+	}
+	return nil
+}
+
+// checkObjectOrigin checks that an embedded object and its attributedTo share
+// the actor's origin.
+func checkObjectOrigin(obj *Object, actor, actorOrigin string) error {
+	if id := obj.ID(); len(id) > 0 && origin(id) != actorOrigin {
+		return &OriginError{Path: "object.id", ID: id, Actor: actor, Err: ErrOriginMismatch}
+	}
+	authors, err := obj.FetchIDs("attributedTo")
+	if err != nil {
+		return err
+	}
+	for _, author := range authors {
+		if origin(author) != actorOrigin {
+			return &OriginError{Path: "object.attributedTo", ID: author, Actor: actor, Err: ErrOriginMismatch}
+		}
+	}
+	return nil
+}
+
+// origin returns the scheme and host of an absolute IRI, without a default
+// port, or an empty string.
+func origin(iri string) string {
+	u, err := url.Parse(iri)
+	if err != nil || !u.IsAbs() || len(u.Host) == 0 {
+		return ""
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); len(port) > 0 && defaultPorts[scheme] != port {
+		host = net.JoinHostPort(host, port)
+	}
+	return scheme + "://" + host
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
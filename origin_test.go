@@ -0,0 +1,319 @@
+package apub_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestOriginChecker(t *testing.T) {
+	fetcher := fixtureFetcher(t, map[string]string{
+		"https://mastodon.gamedev.place/users/bob#main-key": `{
+			"id": "https://mastodon.gamedev.place/users/bob",
+			"type": "Person",
+			"publicKey": {
+				"id": "https://mastodon.gamedev.place/users/bob#main-key",
+				"owner": "https://mastodon.gamedev.place/users/bob",
+				"publicKeyPem": "` + mastodonKeyPem + `"
+			}
+		}`,
+		"https://mastodon.gamedev.place/users/carol#main-key": `{
+			"id": "https://mastodon.gamedev.place/users/carol",
+			"type": "Person",
+			"publicKey": {
+				"id": "https://mastodon.gamedev.place/users/carol#main-key",
+				"owner": "https://mastodon.gamedev.place/users/carol",
+				"publicKeyPem": "` + mastodonKeyPem + `"
+			}
+		}`,
+		"https://gts.example/users/dave/main-key": `{
+			"id": "https://gts.example/users/dave/main-key",
+			"owner": "https://gts.example/users/dave",
+			"publicKeyPem": "` + mastodonKeyPem + `"
+		}`,
+		"https://gts.example/users/dave": `{
+			"id": "https://gts.example/users/dave",
+			"type": "Person",
+			"publicKey": {
+				"id": "https://gts.example/users/dave/main-key",
+				"owner": "https://gts.example/users/dave",
+				"publicKeyPem": "` + mastodonKeyPem + `"
+			}
+		}`,
+		"https://example.com/~erik": `{
+			"id": "https://example.com/~erik",
+			"type": "Person"
+		}`,
+		"https://evil.example/key": `{
+			"id": "https://victim.example/users/alice",
+			"type": "Person",
+			"publicKey": {
+				"id": "https://evil.example/key",
+				"owner": "https://victim.example/users/alice",
+				"publicKeyPem": "` + mastodonKeyPem + `"
+			}
+		}`,
+		"https://victim.example/notes/1": `{
+			"id": "https://victim.example/notes/1",
+			"type": "Note",
+			"attributedTo": "https://victim.example/users/x",
+			"content": "the real note"
+		}`,
+		"https://evil.example/notes/1": `{
+			"id": "https://victim.example/notes/1",
+			"type": "Note",
+			"attributedTo": "https://victim.example/users/x"
+		}`,
+	})
+	offline := &apub.OriginChecker{}
+	online := &apub.OriginChecker{Fetcher: fetcher}
+	ctx := apub.WithSignerKeyID(context.Background(), "https://mastodon.gamedev.place/users/bob#main-key")
+
+	tests := []struct {
+		name    string
+		checker *apub.OriginChecker
+		input   string
+		path    string
+		err     error
+	}{
+		{
+			name:    "create",
+			checker: online,
+			input: `{
+				"id": "https://mastodon.gamedev.place/users/bob/statuses/1/activity",
+				"type": "Create",
+				"actor": "https://mastodon.gamedev.place/users/bob",
+				"object": {
+					"id": "https://mastodon.gamedev.place/users/bob/statuses/1",
+					"type": "Note",
+					"attributedTo": "https://mastodon.gamedev.place/users/bob"
+				}
+			}`,
+		},
+		{
+			name:    "object reference",
+			checker: offline,
+			input: `{
+				"id": "https://mastodon.gamedev.place/users/bob#likes/1",
+				"type": "Like",
+				"actor": "https://MASTODON.gamedev.place:443/users/bob",
+				"object": "https://victim.example/notes/1"
+			}`,
+		},
+		{
+			name:    "spoofed actor",
+			checker: offline,
+			input: `{
+				"id": "https://victim.example/users/x/statuses/1/activity",
+				"type": "Create",
+				"actor": "https://victim.example/users/x",
+				"object": {"id": "https://victim.example/users/x/statuses/1", "type": "Note"}
+			}`,
+			path: "keyId",
+			err:  apub.ErrSignerMismatch,
+		},
+		{
+			name:    "other actor on same host",
+			checker: online,
+			input: `{
+				"type": "Follow",
+				"actor": "https://mastodon.gamedev.place/users/carol",
+				"object": "https://example.com/~erik"
+			}`,
+			path: "keyId",
+			err:  apub.ErrSignerMismatch,
+		},
+		{
+			name:    "activity id",
+			checker: offline,
+			input: `{
+				"id": "https://evil.example/activities/1",
+				"type": "Follow",
+				"actor": "https://mastodon.gamedev.place/users/bob",
+				"object": "https://example.com/~erik"
+			}`,
+			path: "id",
+			err:  apub.ErrOriginMismatch,
+		},
+		{
+			name:    "create with someone else's note",
+			checker: online,
+			input: `{
+				"type": "Create",
+				"actor": "https://mastodon.gamedev.place/users/bob",
+				"object": {
+					"id": "https://mastodon.gamedev.place/users/bob/statuses/1",
+					"type": "Note",
+					"attributedTo": "https://victim.example/users/x"
+				}
+			}`,
+			path: "object.attributedTo",
+			err:  apub.ErrOriginMismatch,
+		},
+		{
+			name:    "announce without fetcher",
+			checker: offline,
+			input: `{
+				"type": "Announce",
+				"actor": "https://mastodon.gamedev.place/users/bob",
+				"object": {
+					"id": "https://victim.example/notes/1",
+					"type": "Note",
+					"content": "a forged note"
+				}
+			}`,
+			path: "object.id",
+			err:  apub.ErrOriginMismatch,
+		},
+		{
+			name:    "refetched with another id",
+			checker: online,
+			input: `{
+				"type": "Announce",
+				"actor": "https://mastodon.gamedev.place/users/bob",
+				"object": {"id": "https://evil.example/notes/1", "type": "Note"}
+			}`,
+			path: "object.id",
+			err:  apub.ErrOriginMismatch,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.checker.Check(ctx, Parse(t, test.input))
+			if test.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var oerr *apub.OriginError
+			require.True(t, xerrors.As(err, &oerr), err)
+			assert.Equal(t, test.path, oerr.Path)
+			assert.True(t, xerrors.Is(err, test.err), err)
+		})
+	}
+
+	t.Run("refetch announced object", func(t *testing.T) {
+		act := Parse(t, `{
+			"type": "Announce",
+			"actor": "https://mastodon.gamedev.place/users/bob",
+			"object": {
+				"id": "https://victim.example/notes/1",
+				"type": "Note",
+				"content": "a forged note"
+			}
+		}`)
+		require.Nil(t, online.Check(ctx, act))
+		assert.Equal(t, "the real note", act.Object("object").Content(""))
+	})
+
+	t.Run("object iri", func(t *testing.T) {
+		tests := []struct {
+			activity string
+			object   string
+			offline  error
+			online   error
+		}{
+			{activity: "Delete", object: "https://victim.example/notes/1", offline: apub.ErrOriginMismatch, online: apub.ErrOriginMismatch},
+			{activity: "Update", object: "https://victim.example/notes/1", offline: apub.ErrOriginMismatch, online: apub.ErrOriginMismatch},
+			{activity: "Undo", object: "https://victim.example/notes/1", offline: apub.ErrOriginMismatch, online: apub.ErrOriginMismatch},
+			{activity: "Create", object: "https://victim.example/notes/1", offline: apub.ErrOriginMismatch, online: apub.ErrOriginMismatch},
+			{activity: "Delete", object: "https://mastodon.gamedev.place/users/bob/statuses/1"},
+			{activity: "Announce", object: "https://victim.example/notes/1"},
+			{activity: "Like", object: "https://victim.example/notes/1"},
+			{activity: "Like", object: "https://evil.example/notes/1", online: apub.ErrOriginMismatch},
+			{activity: "Follow", object: "https://example.com/~erik"},
+		}
+
+		for _, test := range tests {
+			for _, checker := range []*apub.OriginChecker{offline, online} {
+				expected := test.offline
+				if checker == online {
+					expected = test.online
+				}
+
+				act := Parse(t, `{
+					"type": "`+test.activity+`",
+					"actor": "https://mastodon.gamedev.place/users/bob",
+					"object": "`+test.object+`"
+				}`)
+				err := checker.Check(ctx, act)
+				if expected == nil {
+					assert.Nil(t, err, test.activity)
+					continue
+				}
+
+				var oerr *apub.OriginError
+				require.True(t, xerrors.As(err, &oerr), "%s: %v", test.activity, err)
+				assert.Equal(t, "object.id", oerr.Path, test.activity)
+				assert.True(t, xerrors.Is(err, expected), err)
+			}
+		}
+
+		act := Parse(t, `{
+			"type": "Announce",
+			"actor": "https://mastodon.gamedev.place/users/bob",
+			"object": "https://victim.example/notes/1"
+		}`)
+		require.Nil(t, online.Check(ctx, act))
+		assert.Equal(t, "the real note", act.Object("object").Content(""))
+	})
+
+	t.Run("delete embedded object", func(t *testing.T) {
+		act := Parse(t, `{
+			"type": "Delete",
+			"actor": "https://mastodon.gamedev.place/users/bob",
+			"object": {"id": "https://victim.example/notes/1", "type": "Tombstone"}
+		}`)
+		err := online.Check(ctx, act)
+		assert.True(t, xerrors.Is(err, apub.ErrOriginMismatch), err)
+	})
+
+	t.Run("key document", func(t *testing.T) {
+		keyCtx := apub.WithSignerKeyID(ctx, "https://gts.example/users/dave/main-key")
+		err := online.Check(keyCtx, Parse(t, `{
+			"type": "Follow",
+			"actor": "https://gts.example/users/dave",
+			"object": "https://example.com/~erik"
+		}`))
+		assert.Nil(t, err)
+
+		err = online.Check(keyCtx, Parse(t, `{
+			"type": "Follow",
+			"actor": "https://gts.example/users/eve",
+			"object": "https://example.com/~erik"
+		}`))
+		var oerr *apub.OriginError
+		require.True(t, xerrors.As(err, &oerr), err)
+		assert.Equal(t, "keyId", oerr.Path)
+		assert.True(t, xerrors.Is(err, apub.ErrSignerMismatch), err)
+	})
+
+	t.Run("cross-origin key document", func(t *testing.T) {
+		act := Parse(t, `{
+			"type": "Follow",
+			"actor": "https://victim.example/users/alice",
+			"object": "https://example.com/~erik"
+		}`)
+		for _, checker := range []*apub.OriginChecker{offline, online} {
+			err := checker.Check(apub.WithSignerKeyID(ctx, "https://evil.example/key"), act)
+			var oerr *apub.OriginError
+			require.True(t, xerrors.As(err, &oerr), err)
+			assert.Equal(t, "keyId", oerr.Path)
+			assert.True(t, xerrors.Is(err, apub.ErrSignerMismatch), err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := offline.Check(ctx, Parse(t, `{"type": "Follow"}`))
+		assert.True(t, xerrors.Is(err, apub.ErrMissingActor), err)
+
+		err = online.Check(apub.WithSignerKeyID(ctx, "https://c.example/missing#key"),
+			Parse(t, `{"type": "Follow", "actor": "https://c.example/missing"}`))
+		assert.True(t, xerrors.Is(err, apub.ErrFetchFailed), err)
+	})
+}
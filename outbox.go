@@ -0,0 +1,139 @@
+package apub
+
+import (
+	"context"
+	"net/http"
+)
+
+// IDMinter assigns ids to activities and objects posted to an outbox.
+type IDMinter interface {
+	MintID(ctx context.Context, o *Object) (string, error)
+}
+
+// OutboxHandler is an http.Handler for client to server posts to an
+// ActivityPub outbox. Objects that are not activities are wrapped in a Create
+// activity, with the object's attributedTo as its actor. The activity, and the object of a Create, get new ids from the
+// Minter. The activity is saved with Store, and then passed to the function
+// registered for its type with Handle, to apply side effects such as delivery.
+type OutboxHandler struct {
+	// Minter is required.
+	Minter IDMinter
+
+	// Store saves the activity, after bto and bcc are removed.
+	Store ActivityFunc
+
+	// MaxBodySize is the largest accepted request body. DefaultMaxBodySize is
+	// used if zero.
+	MaxBodySize int64
+
+	// Parser parses the posted activities. A zero Parser is used if nil.
+	Parser *Parser
+
+	handlers map[string]ActivityFunc
+}
+
+// Handle registers the side effects for activities of the given type. The
+// activity still has its bto and bcc recipients.
+func (h *OutboxHandler) Handle(ty string, fn ActivityFunc) {
+	if h.handlers == nil {
+		h.handlers = make(map[string]ActivityFunc)
+	}
+	h.handlers[ty] = fn
+}
+
+func (h *OutboxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, status := readActivity(w, r, h.MaxBodySize)
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	act, err := parseActivity(h.Parser, body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !act.IsType("Activity") {
+		authors := act.AttributedTo()
+		if len(authors) == 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		act = CreateActivity(act)
+		act.SetStr("actor", authors[0])
+	}
+
+	ctx := r.Context()
+	if err := h.mintIDs(ctx, act); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if h.Store != nil {
+		if err := h.Store(ctx, withoutHiddenRecipients(act)); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if fn := h.handlers[act.Type()]; fn != nil {
+		if err := fn(ctx, act); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Location", act.ID())
+	w.WriteHeader(http.StatusCreated)
+}
+
+// mintIDs replaces the ids of the activity, and the object of a Create, since
+// clients can't choose them.
+func (h *OutboxHandler) mintIDs(ctx context.Context, act *Object) error {
+	if act.Type() == "Create" {
+		if obj, ok := act.data["object"].(map[string]interface{}); ok {
+			id, err := h.Minter.MintID(ctx, act.newObj("object", obj))
+			if err != nil {
+				return err
+			}
+			obj["id"] = id
+		}
+	}
+
+	id, err := h.Minter.MintID(ctx, act)
+	if err != nil {
+		return err
+	}
+	return act.SetStr("id", id)
+}
+
+// withoutHiddenRecipients returns a copy of the activity without bto and bcc,
+// which must not be stored or shown to recipients.
+func withoutHiddenRecipients(act *Object) *Object {
+	data := stripHiddenRecipients(act.data)
+	if obj, ok := data["object"].(map[string]interface{}); ok {
+		data["object"] = stripHiddenRecipients(obj)
+	}
+
+	stripped := New(data)
+	stripped.lang = act.lang
+	stripped.setContext(act.ctx)
+	return stripped
+}
+
+func stripHiddenRecipients(data map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "bto" && k != "bcc" {
+			copied[k] = v
+		}
+	}
+	return copied
+}
@@ -0,0 +1,127 @@
+package apub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/technoweenie/apub"
+)
+
+type testMinter struct {
+	n int
+}
+
+func (m *testMinter) MintID(ctx context.Context, o *apub.Object) (string, error) {
+	if o.Type() == "Invalid" {
+		return "", errors.New("boom")
+	}
+	m.n++
+	return fmt.Sprintf("https://example.com/%s/%d", strings.ToLower(o.Type()), m.n), nil
+}
+
+func TestOutboxHandler(t *testing.T) {
+	var stored, handled []*apub.Object
+	outbox := &apub.OutboxHandler{
+		Minter: &testMinter{},
+		Store: func(ctx context.Context, act *apub.Object) error {
+			stored = append(stored, act)
+			return nil
+		},
+	}
+	outbox.Handle("Create", func(ctx context.Context, act *apub.Object) error {
+		handled = append(handled, act)
+		return nil
+	})
+
+	post := func(t *testing.T, body string) *httptest.ResponseRecorder {
+		stored, handled = nil, nil
+		req := httptest.NewRequest("POST", "/users/erik/outbox", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/activity+json")
+		res := httptest.NewRecorder()
+		outbox.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("object", func(t *testing.T) {
+		res := post(t, `{
+			"id": "https://example.com/client/1",
+			"type": "Note",
+			"attributedTo": "https://example.com/~erik",
+			"content": "hi",
+			"to": ["https://example.com/~bob"],
+			"bcc": ["https://example.com/~alice"]
+		}`)
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Equal(t, "https://example.com/create/2", res.Header().Get("Location"))
+
+		if assert.Equal(t, 1, len(stored)) {
+			act := stored[0]
+			assert.Equal(t, "Create", act.Type())
+			assert.Equal(t, "https://example.com/create/2", act.ID())
+			assert.Equal(t, "https://example.com/~erik", act.Str("actor"))
+			assert.Equal(t, []string{"https://example.com/~bob"}, act.To())
+			assert.Equal(t, 0, len(act.BCC()))
+
+			note := act.Object("object")
+			assert.Equal(t, "https://example.com/note/1", note.ID())
+			assert.Equal(t, "hi", note.Content(""))
+			assert.Equal(t, 0, len(note.BCC()))
+		}
+
+		if assert.Equal(t, 1, len(handled)) {
+			act := handled[0]
+			assert.Equal(t, "https://example.com/create/2", act.ID())
+			assert.Equal(t, []string{"https://example.com/~alice"}, act.BCC())
+			assert.Equal(t, []string{"https://example.com/~alice"}, act.Object("object").BCC())
+		}
+	})
+
+	t.Run("activity", func(t *testing.T) {
+		res := post(t, `{
+			"type": "Follow",
+			"actor": "https://example.com/~erik",
+			"object": "https://example.com/~bob",
+			"bto": ["https://example.com/~bob"]
+		}`)
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Equal(t, "https://example.com/follow/3", res.Header().Get("Location"))
+		if assert.Equal(t, 1, len(stored)) {
+			assert.Equal(t, "https://example.com/~bob", stored[0].Str("object"))
+			assert.Equal(t, 0, len(stored[0].BTo()))
+		}
+		assert.Equal(t, 0, len(handled))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		res := post(t, `{"type": "Invalid", "attributedTo": "https://example.com/~erik"}`)
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+		assert.Equal(t, 0, len(stored))
+
+		res = post(t, `{"content": "hi"}`)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		// a Create needs an actor
+		res = post(t, `{"type": "Note"}`)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, 0, len(stored))
+
+		outbox.Store = func(ctx context.Context, act *apub.Object) error {
+			return errors.New("boom")
+		}
+		res = post(t, `{"type": "Note", "attributedTo": "https://example.com/~erik"}`)
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+		assert.Equal(t, 0, len(handled))
+
+		req := httptest.NewRequest("POST", "/users/erik/outbox", strings.NewReader(`{"type": "Note"}`))
+		req.Header.Set("Content-Type", "text/plain")
+		rec := httptest.NewRecorder()
+		outbox.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+}
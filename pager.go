@@ -0,0 +1,114 @@
+package apub
+
+import (
+	"context"
+	"net/url"
+)
+
+// PageSource loads the items of a paged collection, such as an actor's
+// followers or outbox, with opaque cursors.
+type PageSource interface {
+	// Summary returns the number of items in the collection, and the cursor of
+	// the last page. If last is empty, the collection has no last link.
+	Summary(ctx context.Context) (total int, last string, err error)
+
+	// Page returns the items at the given cursor. An empty cursor is the first
+	// page.
+	Page(ctx context.Context, cursor string) (*PageResult, error)
+}
+
+// PageResult is a page of items from a PageSource. Next and Prev are the
+// cursors of the adjacent pages, or empty at either end of the collection.
+type PageResult struct {
+	// Items are embedded in the page. Items with only an id, such as
+	// followers, are written as bare IRIs.
+	Items []*Object
+	Next  string
+	Prev  string
+}
+
+// CollectionPager builds a paged OrderedCollection from a PageSource.
+type CollectionPager struct {
+	// ID is the IRI of the collection, such as
+	// "https://example.com/users/bob/followers".
+	ID string
+
+	Source PageSource
+
+	// PageURL returns the IRI of the page at the given cursor. If nil, the
+	// first page is ID with "page=true", and later pages add the cursor as
+	// "cursor".
+	PageURL func(cursor string) string
+}
+
+// Collection returns the OrderedCollection with totalItems, and first and last
+// links to its pages.
+func (p *CollectionPager) Collection(ctx context.Context) (*Object, error) {
+	total, last, err := p.Source.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"@context":   "https://www.w3.org/ns/activitystreams",
+		"id":         p.ID,
+		"type":       "OrderedCollection",
+		"totalItems": float64(total),
+		"first":      p.pageURL(""),
+	}
+	if len(last) > 0 {
+		data["last"] = p.pageURL(last)
+	}
+	return New(data), nil
+}
+
+// Page returns the OrderedCollectionPage at the given cursor, with its items
+// and next and prev links.
+func (p *CollectionPager) Page(ctx context.Context, cursor string) (*Object, error) {
+	res, err := p.Source.Page(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, len(res.Items))
+	for i, item := range res.Items {
+		if isReference(item) {
+			items[i] = item.ID()
+		} else {
+			items[i] = embedObject(item)
+		}
+	}
+
+	data := map[string]interface{}{
+		"@context":     "https://www.w3.org/ns/activitystreams",
+		"id":           p.pageURL(cursor),
+		"type":         "OrderedCollectionPage",
+		"partOf":       p.ID,
+		"orderedItems": items,
+	}
+	if len(res.Next) > 0 {
+		data["next"] = p.pageURL(res.Next)
+	}
+	if len(res.Prev) > 0 {
+		data["prev"] = p.pageURL(res.Prev)
+	}
+	return New(data), nil
+}
+
+func (p *CollectionPager) pageURL(cursor string) string {
+	if p.PageURL != nil {
+		return p.PageURL(cursor)
+	}
+
+	u, err := url.Parse(p.ID)
+	if err != nil {
+		return p.ID
+	}
+	q := u.Query()
+	q.Set("page", "true")
+	if len(cursor) > 0 {
+		q.Set("cursor", cursor)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
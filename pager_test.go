@@ -0,0 +1,149 @@
+package apub_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+)
+
+func TestCollectionPager(t *testing.T) {
+	ctx := context.Background()
+	source := &slicePageSource{size: 2}
+	for i := 1; i <= 5; i++ {
+		source.items = append(source.items, apub.New(map[string]interface{}{
+			"id": fmt.Sprintf("https://example.com/users/%d", i),
+		}))
+	}
+	pager := &apub.CollectionPager{
+		ID:     "https://example.com/users/bob/followers",
+		Source: source,
+	}
+
+	t.Run("collection", func(t *testing.T) {
+		coll, err := pager.Collection(ctx)
+		require.Nil(t, err)
+		assertJSON(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id": "https://example.com/users/bob/followers",
+			"type": "OrderedCollection",
+			"totalItems": 5,
+			"first": "https://example.com/users/bob/followers?page=true",
+			"last": "https://example.com/users/bob/followers?cursor=4&page=true"
+		}`, coll)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		page, err := pager.Page(ctx, "")
+		require.Nil(t, err)
+		assertJSON(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id": "https://example.com/users/bob/followers?page=true",
+			"type": "OrderedCollectionPage",
+			"partOf": "https://example.com/users/bob/followers",
+			"next": "https://example.com/users/bob/followers?cursor=2&page=true",
+			"orderedItems": ["https://example.com/users/1", "https://example.com/users/2"]
+		}`, page)
+
+		page, err = pager.Page(ctx, "2")
+		require.Nil(t, err)
+		assert.Equal(t, "https://example.com/users/bob/followers?cursor=4&page=true", page.Str("next"))
+		assert.Equal(t, "https://example.com/users/bob/followers?cursor=0&page=true", page.Str("prev"))
+
+		page, err = pager.Page(ctx, "4")
+		require.Nil(t, err)
+		assert.Equal(t, "", page.Str("next"))
+		assert.Equal(t, []string{"https://example.com/users/5"}, page.IDs("orderedItems"))
+	})
+
+	t.Run("embedded items", func(t *testing.T) {
+		outbox := &apub.CollectionPager{
+			ID: "https://example.com/users/bob/outbox",
+			Source: &slicePageSource{size: 20, items: []*apub.Object{
+				apub.New(map[string]interface{}{
+					"@context": "https://www.w3.org/ns/activitystreams",
+					"id":       "https://example.com/create/1",
+					"type":     "Create",
+				}),
+			}},
+			PageURL: func(cursor string) string {
+				return "https://example.com/users/bob/outbox/page/" + cursor
+			},
+		}
+
+		page, err := outbox.Page(ctx, "")
+		require.Nil(t, err)
+		assertJSON(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id": "https://example.com/users/bob/outbox/page/",
+			"type": "OrderedCollectionPage",
+			"partOf": "https://example.com/users/bob/outbox",
+			"orderedItems": [{"id": "https://example.com/create/1", "type": "Create"}]
+		}`, page)
+	})
+
+	t.Run("iterator", func(t *testing.T) {
+		fetcher := apub.FetcherFunc(func(ctx context.Context, iri string) (*apub.Object, error) {
+			u, err := url.Parse(iri)
+			require.Nil(t, err)
+			return pager.Page(ctx, u.Query().Get("cursor"))
+		})
+
+		coll, err := pager.Collection(ctx)
+		require.Nil(t, err)
+		iter := apub.NewCollectionIterator(ctx, fetcher, coll)
+		assert.Equal(t, []string{
+			"https://example.com/users/1",
+			"https://example.com/users/2",
+			"https://example.com/users/3",
+			"https://example.com/users/4",
+			"https://example.com/users/5",
+		}, collect(t, iter))
+	})
+}
+
+// slicePageSource pages through items with the item offset as the cursor.
+type slicePageSource struct {
+	items []*apub.Object
+	size  int
+}
+
+func (s *slicePageSource) Summary(ctx context.Context) (int, string, error) {
+	last := (len(s.items) - 1) / s.size * s.size
+	return len(s.items), strconv.Itoa(last), nil
+}
+
+func (s *slicePageSource) Page(ctx context.Context, cursor string) (*apub.PageResult, error) {
+	start := 0
+	if len(cursor) > 0 {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	end := start + s.size
+	if end > len(s.items) {
+		end = len(s.items)
+	}
+	res := &apub.PageResult{Items: s.items[start:end]}
+	if end < len(s.items) {
+		res.Next = strconv.Itoa(end)
+	}
+	if start > 0 {
+		res.Prev = strconv.Itoa(start - s.size)
+	}
+	return res, nil
+}
+
+func assertJSON(t *testing.T, expected string, o *apub.Object) {
+	actual, err := json.Marshal(o)
+	require.Nil(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
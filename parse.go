@@ -7,6 +7,16 @@ import (
 
 type Parser struct {
 	Language string
+
+	// ProcessContext resolves the document's JSON-LD @context, so that
+	// properties can be looked up by compact or absolute IRI.
+	ProcessContext bool
+
+	// Loader loads remote contexts. DefaultDocumentLoader is used if nil.
+	Loader DocumentLoader
+
+	// Fetcher dereferences bare IRIs for Object.Resolve, such as a Resolver.
+	Fetcher Fetcher
 }
 
 func (p *Parser) Parse(input io.Reader) (*Object, error) {
@@ -14,9 +24,18 @@ func (p *Parser) Parse(input io.Reader) (*Object, error) {
 	err := json.NewDecoder(input).Decode(&data)
 
 	obj := New(data)
+	obj.fetcher = p.Fetcher
 	if len(p.Language) > 0 {
 		obj.lang = p.Language
 	}
 
+	if p.ProcessContext {
+		ctx, cerr := newActiveContext(p.Loader).parse(data["@context"])
+		if cerr != nil {
+			obj.addError(cerr)
+		}
+		obj.setContext(ctx)
+	}
+
 	return obj, err
 }
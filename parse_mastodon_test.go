@@ -13,70 +13,7 @@ import (
 
 func TestParseMastodon(t *testing.T) {
 	t.Run("person", func(t *testing.T) {
-		obj := Parse(t, `{
-			"@context": [
-				"https://www.w3.org/ns/activitystreams",
-				"https://w3id.org/security/v1",
-				{
-					"manuallyApprovesFollowers": "as:manuallyApprovesFollowers",
-					"toot": "http://joinmastodon.org/ns#",
-					"featured": {
-						"@id": "toot:featured",
-						"@type": "@id"
-					},
-					"alsoKnownAs": {
-						"@id": "as:alsoKnownAs",
-						"@type": "@id"
-					},
-					"movedTo": {
-						"@id": "as:movedTo",
-						"@type": "@id"
-					},
-					"schema": "http://schema.org#",
-					"PropertyValue": "schema:PropertyValue",
-					"value": "schema:value",
-					"Hashtag": "as:Hashtag",
-					"Emoji": "toot:Emoji",
-					"IdentityProof": "toot:IdentityProof",
-					"focalPoint": {
-						"@container": "@list",
-						"@id": "toot:focalPoint"
-					}
-				}
-			],
-			"id": "https://mastodon.gamedev.place/users/bob",
-			"type": "Person",
-			"following": "https://mastodon.gamedev.place/users/bob/following",
-			"followers": "https://mastodon.gamedev.place/users/bob/followers",
-			"inbox": "https://mastodon.gamedev.place/users/bob/inbox",
-			"outbox": "https://mastodon.gamedev.place/users/bob/outbox",
-			"featured": "https://mastodon.gamedev.place/users/bob/collections/featured",
-			"preferredUsername": "bob",
-			"name": "Robert Tables",
-			"summary": "<p>Bob</p>",
-			"url": "https://mastodon.gamedev.place/@bob",
-			"manuallyApprovesFollowers": false,
-			"publicKey": {
-				"id": "https://mastodon.gamedev.place/users/bob#main-key",
-				"owner": "https://mastodon.gamedev.place/users/bob",
-				"publicKeyPem": "-----BEGIN PUBLIC KEY-----\\nMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAs+LxLJfjz+6Yf+1nh8rp\\na/ugMbp1geZFm2AsGZyIyB7CP/wRuzO9WmGkRQNpJmgEaYsiPN0l0ZcwoUtkXp41\\nZdUIOjuftLdNZAAaYFXzMEfmN3yE9LG5zOT9B3RSH/93psujPt0xUcurpN4L/III\\nwo9HawigZXPSY5J79Y4kDUOIpdw0o/36h0cZwAhrG+VHfAaHI5hShNW+6VzpWujP\\nzFI7eTtgJYLwE0PyJqLDqInbFINf4JaJqtvk7dLYeCQhPV8FrZMlsmrMVOY4TdAI\\nNKPu6QujEQjvguJy60//XYkH8stu5nlXKUR6GuY4s/Mo1mAb/bXo6lwMITAWPCD0\\noQIDAQAB\\n-----END PUBLIC KEY-----\n"
-			},
-			"tag": [],
-			"attachment": [],
-			"endpoints": {
-				"sharedInbox": "https://mastodon.gamedev.place/inbox"
-			},
-			"icon": {
-				"type": "Image",
-				"mediaType": "image/jpeg",
-				"url": "https://example.com/icon.jpg"
-			},
-			"image": {
-				"type": "Image",
-				"mediaType": "image/jpeg",
-				"url": "https://example.com/image.jpg"
-			}
-		}`)
+		obj := Parse(t, mastodonPersonJSON)
 
 		assert.Equal(t, "https://mastodon.gamedev.place/users/bob", obj.ID())
 		assert.Equal(t, "Person", obj.Type())
@@ -101,54 +38,7 @@ func TestParseMastodon(t *testing.T) {
 	})
 
 	t.Run("note collection", func(t *testing.T) {
-		obj := Parse(t, `{
-			"@context": "https://www.w3.org/ns/activitystreams",
-			"id": "https://mastodon.gamedev.place/users/bob/collections/featured",
-			"type": "OrderedCollection",
-			"totalItems": 1,
-			"orderedItems": [
-				{
-					"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
-					"type": "Note",
-					"summary": null,
-					"inReplyTo": null,
-					"published": "2019-04-14T17:19:09Z",
-					"url": "https://mastodon.gamedev.place/@bob/4815162342",
-					"attributedTo": "https://mastodon.gamedev.place/users/bob",
-					"to": [
-						"https://www.w3.org/ns/activitystreams#Public"
-					],
-					"cc": [
-						"https://mastodon.gamedev.place/users/bob/followers"
-					],
-					"sensitive": false,
-					"atomUri": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
-					"inReplyToAtomUri": null,
-					"conversation": "tag:mastodon.gamedev.place,2019-04-14:objectId=4815162342:objectType=Conversation",
-					"content": "<p>Content</p>",
-					"contentMap": {
-						"en": "<p>EN Content</p>"
-					},
-					"attachment": [],
-					"tag": [
-						{
-							"type": "Hashtag",
-							"href": "https://mastodon.gamedev.place/tags/activitypub",
-							"name": "#activitypub"
-						}
-					],
-					"replies": {
-						"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
-						"type": "Collection",
-						"first": {
-							"type": "CollectionPage",
-							"partOf": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
-							"items": []
-						}
-					}
-				}
-			]
-		}`)
+		obj := Parse(t, mastodonFeaturedJSON)
 
 		assert.Equal(t, "OrderedCollection", obj.Type())
 		assert.Equal(t, "https://mastodon.gamedev.place/users/bob/collections/featured", obj.ID())
@@ -192,58 +82,7 @@ func TestParseMastodon(t *testing.T) {
 	})
 
 	t.Run("note", func(t *testing.T) {
-		obj := Parse(t, `{
-			"@context": [
-				"https://www.w3.org/ns/activitystreams",
-				{
-					"ostatus": "http://ostatus.org#",
-					"atomUri": "ostatus:atomUri",
-					"inReplyToAtomUri": "ostatus:inReplyToAtomUri",
-					"conversation": "ostatus:conversation",
-					"sensitive": "as:sensitive",
-					"Hashtag": "as:Hashtag",
-					"toot": "http://joinmastodon.org/ns#",
-					"Emoji": "toot:Emoji",
-					"focalPoint": {
-						"@container": "@list",
-						"@id": "toot:focalPoint"
-					},
-					"blurhash": "toot:blurhash"
-				}
-			],
-			"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
-			"type": "Note",
-			"summary": null,
-			"inReplyTo": null,
-			"published": "2019-06-13T04:46:37Z",
-			"url": "https://mastodon.gamedev.place/@bob/4815162342",
-			"attributedTo": "https://mastodon.gamedev.place/users/bob",
-			"to": [
-				"https://www.w3.org/ns/activitystreams#Public"
-			],
-			"cc": [
-				"https://mastodon.gamedev.place/users/bob/followers"
-			],
-			"sensitive": false,
-			"atomUri": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
-			"inReplyToAtomUri": null,
-			"conversation": "tag:mastodon.gamedev.place,2019-06-13:objectId=4815162342:objectType=Conversation",
-			"content": "<p>Content</p>",
-			"contentMap": {
-				"en": "<p>Content EN</p>"
-			},
-			"attachment": [],
-			"tag": [],
-			"replies": {
-				"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
-				"type": "Collection",
-				"first": {
-					"type": "CollectionPage",
-					"partOf": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
-					"items": []
-				}
-			}
-		}`)
+		obj := Parse(t, mastodonNoteJSON)
 
 		assert.Equal(t, "https://mastodon.gamedev.place/users/bob/statuses/4815162342", obj.ID())
 		assert.Equal(t, "Note", obj.Type())
@@ -263,3 +102,173 @@ func TestParseMastodon(t *testing.T) {
 		assert.Nil(t, obj.NonFatalErrors())
 	})
 }
+
+// Mastodon fixtures, also checked by TestValidate.
+const (
+	mastodonPersonJSON = `{
+		"@context": [
+			"https://www.w3.org/ns/activitystreams",
+			"https://w3id.org/security/v1",
+			{
+				"manuallyApprovesFollowers": "as:manuallyApprovesFollowers",
+				"toot": "http://joinmastodon.org/ns#",
+				"featured": {
+					"@id": "toot:featured",
+					"@type": "@id"
+				},
+				"alsoKnownAs": {
+					"@id": "as:alsoKnownAs",
+					"@type": "@id"
+				},
+				"movedTo": {
+					"@id": "as:movedTo",
+					"@type": "@id"
+				},
+				"schema": "http://schema.org#",
+				"PropertyValue": "schema:PropertyValue",
+				"value": "schema:value",
+				"Hashtag": "as:Hashtag",
+				"Emoji": "toot:Emoji",
+				"IdentityProof": "toot:IdentityProof",
+				"focalPoint": {
+					"@container": "@list",
+					"@id": "toot:focalPoint"
+				}
+			}
+		],
+		"id": "https://mastodon.gamedev.place/users/bob",
+		"type": "Person",
+		"following": "https://mastodon.gamedev.place/users/bob/following",
+		"followers": "https://mastodon.gamedev.place/users/bob/followers",
+		"inbox": "https://mastodon.gamedev.place/users/bob/inbox",
+		"outbox": "https://mastodon.gamedev.place/users/bob/outbox",
+		"featured": "https://mastodon.gamedev.place/users/bob/collections/featured",
+		"preferredUsername": "bob",
+		"name": "Robert Tables",
+		"summary": "<p>Bob</p>",
+		"url": "https://mastodon.gamedev.place/@bob",
+		"manuallyApprovesFollowers": false,
+		"publicKey": {
+			"id": "https://mastodon.gamedev.place/users/bob#main-key",
+			"owner": "https://mastodon.gamedev.place/users/bob",
+			"publicKeyPem": "-----BEGIN PUBLIC KEY-----\\nMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAs+LxLJfjz+6Yf+1nh8rp\\na/ugMbp1geZFm2AsGZyIyB7CP/wRuzO9WmGkRQNpJmgEaYsiPN0l0ZcwoUtkXp41\\nZdUIOjuftLdNZAAaYFXzMEfmN3yE9LG5zOT9B3RSH/93psujPt0xUcurpN4L/III\\nwo9HawigZXPSY5J79Y4kDUOIpdw0o/36h0cZwAhrG+VHfAaHI5hShNW+6VzpWujP\\nzFI7eTtgJYLwE0PyJqLDqInbFINf4JaJqtvk7dLYeCQhPV8FrZMlsmrMVOY4TdAI\\nNKPu6QujEQjvguJy60//XYkH8stu5nlXKUR6GuY4s/Mo1mAb/bXo6lwMITAWPCD0\\noQIDAQAB\\n-----END PUBLIC KEY-----\n"
+		},
+		"tag": [],
+		"attachment": [],
+		"endpoints": {
+			"sharedInbox": "https://mastodon.gamedev.place/inbox"
+		},
+		"icon": {
+			"type": "Image",
+			"mediaType": "image/jpeg",
+			"url": "https://example.com/icon.jpg"
+		},
+		"image": {
+			"type": "Image",
+			"mediaType": "image/jpeg",
+			"url": "https://example.com/image.jpg"
+		}
+	}`
+
+	mastodonFeaturedJSON = `{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"id": "https://mastodon.gamedev.place/users/bob/collections/featured",
+		"type": "OrderedCollection",
+		"totalItems": 1,
+		"orderedItems": [
+			{
+				"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+				"type": "Note",
+				"summary": null,
+				"inReplyTo": null,
+				"published": "2019-04-14T17:19:09Z",
+				"url": "https://mastodon.gamedev.place/@bob/4815162342",
+				"attributedTo": "https://mastodon.gamedev.place/users/bob",
+				"to": [
+					"https://www.w3.org/ns/activitystreams#Public"
+				],
+				"cc": [
+					"https://mastodon.gamedev.place/users/bob/followers"
+				],
+				"sensitive": false,
+				"atomUri": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+				"inReplyToAtomUri": null,
+				"conversation": "tag:mastodon.gamedev.place,2019-04-14:objectId=4815162342:objectType=Conversation",
+				"content": "<p>Content</p>",
+				"contentMap": {
+					"en": "<p>EN Content</p>"
+				},
+				"attachment": [],
+				"tag": [
+					{
+						"type": "Hashtag",
+						"href": "https://mastodon.gamedev.place/tags/activitypub",
+						"name": "#activitypub"
+					}
+				],
+				"replies": {
+					"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
+					"type": "Collection",
+					"first": {
+						"type": "CollectionPage",
+						"partOf": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
+						"items": []
+					}
+				}
+			}
+		]
+	}`
+
+	mastodonNoteJSON = `{
+		"@context": [
+			"https://www.w3.org/ns/activitystreams",
+			{
+				"ostatus": "http://ostatus.org#",
+				"atomUri": "ostatus:atomUri",
+				"inReplyToAtomUri": "ostatus:inReplyToAtomUri",
+				"conversation": "ostatus:conversation",
+				"sensitive": "as:sensitive",
+				"Hashtag": "as:Hashtag",
+				"toot": "http://joinmastodon.org/ns#",
+				"Emoji": "toot:Emoji",
+				"focalPoint": {
+					"@container": "@list",
+					"@id": "toot:focalPoint"
+				},
+				"blurhash": "toot:blurhash"
+			}
+		],
+		"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+		"type": "Note",
+		"summary": null,
+		"inReplyTo": null,
+		"published": "2019-06-13T04:46:37Z",
+		"url": "https://mastodon.gamedev.place/@bob/4815162342",
+		"attributedTo": "https://mastodon.gamedev.place/users/bob",
+		"to": [
+			"https://www.w3.org/ns/activitystreams#Public"
+		],
+		"cc": [
+			"https://mastodon.gamedev.place/users/bob/followers"
+		],
+		"sensitive": false,
+		"atomUri": "https://mastodon.gamedev.place/users/bob/statuses/4815162342",
+		"inReplyToAtomUri": null,
+		"conversation": "tag:mastodon.gamedev.place,2019-06-13:objectId=4815162342:objectType=Conversation",
+		"content": "<p>Content</p>",
+		"contentMap": {
+			"en": "<p>Content EN</p>"
+		},
+		"attachment": [],
+		"tag": [],
+		"replies": {
+			"id": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
+			"type": "Collection",
+			"first": {
+				"type": "CollectionPage",
+				"partOf": "https://mastodon.gamedev.place/users/bob/statuses/4815162342/replies",
+				"items": []
+			}
+		}
+	}`
+)
@@ -1,5 +1,25 @@
 package apub
 
+import (
+	"context"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// Public is the special collection that addresses everyone.
+const Public = "https://www.w3.org/ns/activitystreams#Public"
+
+// IsPublic returns true if the id is the Public collection, in its absolute or
+// compact forms.
+func IsPublic(id string) bool {
+	switch id {
+	case Public, "as:Public", "as#Public", "Public":
+		return true
+	}
+	return false
+}
+
 func RecipientMap(o *Object) map[string]bool {
 	rec := make(map[string]bool)
 
@@ -61,3 +81,100 @@ var recipientTagTypes = map[string]bool{
 	"Person":            true,
 	"Service":           true,
 }
+
+// CollectionMembers lists the members of local collections, such as the
+// followers of a local actor.
+type CollectionMembers interface {
+	// Members returns the actor ids in the collection, and false if it is not
+	// a local collection.
+	Members(ctx context.Context, id string) ([]string, bool, error)
+}
+
+// RecipientExpander expands the collections in an activity's recipients, such
+// as "https://example.com/~erik/followers", to the actors in them.
+type RecipientExpander struct {
+	// Local lists the members of local collections. If nil, every recipient is
+	// treated as remote.
+	Local CollectionMembers
+
+	// Fetcher loads remote recipients, and the pages of remote collections.
+	// If nil, the Fetcher of the activity's Parser or Resolver is used, and
+	// remote recipients are returned as is without one.
+	Fetcher Fetcher
+}
+
+// Expand returns the actors that the activity is addressed to, with the
+// members of any collections instead of the collections themselves. The
+// Public collection and the activity's actor are excluded. Recipients that
+// can't be expanded are skipped, and the first error is returned.
+func (e *RecipientExpander) Expand(ctx context.Context, act *Object) ([]string, error) {
+	fetcher := e.Fetcher
+	if fetcher == nil {
+		fetcher = act.fetcher
+	}
+
+	var firstErr error
+	setErr := func(id string, err error) {
+		if firstErr == nil {
+			firstErr = xerrors.Errorf("Expand: %q: %w", id, err)
+		}
+	}
+
+	sender := act.Str("actor")
+	actors := make(map[string]bool)
+	add := func(id string) {
+		if len(id) > 0 && !IsPublic(id) && id != sender {
+			actors[id] = true
+		}
+	}
+
+	for id := range RecipientMap(act) {
+		if IsPublic(id) || id == sender {
+			continue
+		}
+
+		if e.Local != nil {
+			members, ok, err := e.Local.Members(ctx, id)
+			if err != nil {
+				setErr(id, err)
+				continue
+			}
+			if ok {
+				for _, member := range members {
+					add(member)
+				}
+				continue
+			}
+		}
+
+		if fetcher == nil {
+			add(id)
+			continue
+		}
+
+		obj, err := fetcher.Fetch(ctx, id)
+		if err != nil {
+			setErr(id, err)
+			continue
+		}
+		if !obj.IsType("Collection") {
+			add(id)
+			continue
+		}
+
+		iter := NewCollectionIterator(ctx, fetcher, obj)
+		for iter.Next() {
+			add(iter.Item().ID())
+		}
+		if err := iter.Err(); err != nil {
+			setErr(id, err)
+		}
+	}
+
+	ids := make([]string, 0, len(actors))
+	for id := range actors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, firstErr
+}
@@ -1,10 +1,13 @@
 package apub_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
 )
 
 func TestRecipients(t *testing.T) {
@@ -106,3 +109,95 @@ func TestRecipients(t *testing.T) {
 		})
 	})
 }
+
+func TestIsPublic(t *testing.T) {
+	assert.True(t, apub.IsPublic(apub.Public))
+	assert.True(t, apub.IsPublic("as:Public"))
+	assert.True(t, apub.IsPublic("Public"))
+	assert.True(t, apub.IsPublic("as#Public"))
+	assert.False(t, apub.IsPublic("https://example.com/~erik/followers"))
+}
+
+func TestRecipientExpander(t *testing.T) {
+	ctx := context.Background()
+	fetcher := fixtureFetcher(t, map[string]string{
+		"https://a.example/alice": `{"id": "https://a.example/alice", "type": "Person", "inbox": "https://a.example/alice/inbox"}`,
+		"https://a.example/bob":   `{"id": "https://a.example/bob", "type": "Person", "inbox": "https://a.example/bob/inbox"}`,
+		"https://b.example/carol": `{"id": "https://b.example/carol", "type": "Person", "inbox": "https://b.example/carol/inbox"}`,
+		"https://b.example/carol/followers": `{
+			"id": "https://b.example/carol/followers",
+			"type": "OrderedCollection",
+			"first": "https://b.example/carol/followers?page=1"
+		}`,
+		"https://b.example/carol/followers?page=1": `{
+			"id": "https://b.example/carol/followers?page=1",
+			"type": "OrderedCollectionPage",
+			"orderedItems": ["https://a.example/bob", "https://example.com/~erik"]
+		}`,
+	})
+	expander := &apub.RecipientExpander{
+		Local: localMembers{
+			"https://example.com/~erik/followers": {"https://a.example/alice", "https://example.com/~erik"},
+		},
+		Fetcher: fetcher,
+	}
+
+	act := Parse(t, `{
+		"id": "https://example.com/create/1",
+		"type": "Create",
+		"actor": "https://example.com/~erik",
+		"to": ["https://www.w3.org/ns/activitystreams#Public"],
+		"cc": ["https://example.com/~erik/followers", "https://b.example/carol/followers", "https://b.example/carol"],
+		"object": {"type": "Note", "attributedTo": "https://example.com/~erik"}
+	}`)
+
+	t.Run("expand", func(t *testing.T) {
+		actors, err := expander.Expand(ctx, act)
+		require.Nil(t, err)
+		assert.Equal(t, []string{
+			"https://a.example/alice",
+			"https://a.example/bob",
+			"https://b.example/carol",
+		}, actors)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		act := Parse(t, `{
+			"type": "Create",
+			"actor": "https://example.com/~erik",
+			"to": ["https://b.example/carol", "https://c.example/missing"]
+		}`)
+		actors, err := expander.Expand(ctx, act)
+		assert.True(t, xerrors.Is(err, apub.ErrFetchFailed), err)
+		assert.Equal(t, []string{"https://b.example/carol"}, actors)
+	})
+
+	t.Run("without fetcher", func(t *testing.T) {
+		expander := &apub.RecipientExpander{Local: expander.Local}
+		actors, err := expander.Expand(ctx, act)
+		require.Nil(t, err)
+		assert.Equal(t, []string{
+			"https://a.example/alice",
+			"https://b.example/carol",
+			"https://b.example/carol/followers",
+		}, actors)
+	})
+
+	t.Run("deliverer", func(t *testing.T) {
+		deliverer := &apub.Deliverer{Fetcher: fetcher, Expander: expander}
+		inboxes, err := deliverer.Inboxes(ctx, act)
+		require.Nil(t, err)
+		assert.Equal(t, []string{
+			"https://a.example/alice/inbox",
+			"https://a.example/bob/inbox",
+			"https://b.example/carol/inbox",
+		}, inboxes)
+	})
+}
+
+type localMembers map[string][]string
+
+func (m localMembers) Members(ctx context.Context, id string) ([]string, bool, error) {
+	members, ok := m[id]
+	return members, ok, nil
+}
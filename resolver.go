@@ -0,0 +1,297 @@
+package apub
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultResolverTTL is how long a Resolver without a TTL caches objects.
+	DefaultResolverTTL = 5 * time.Minute
+
+	// DefaultMaxDepth is how many nested objects a Resolver without a
+	// MaxDepth will fetch through Object.Resolve.
+	DefaultMaxDepth = 8
+
+	// DefaultResolverMaxEntries is how many objects a Resolver without
+	// MaxEntries caches.
+	DefaultResolverMaxEntries = 1000
+
+	activityAccept = `application/activity+json, application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+)
+
+// Resolver is a Fetcher that dereferences IRIs over HTTP, and caches the
+// objects by IRI. Objects with an id from another origin than the URL they
+// were served from, after redirects, are rejected. Objects from a Resolver can resolve their own bare IRIs with
+// Object.Resolve.
+type Resolver struct {
+	// Client fetches objects. Use an httpsig.Transport for signed fetches.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// Parser parses the fetched objects. A zero Parser is used if nil.
+	Parser *Parser
+
+	// TTL is how long objects are cached before they are revalidated with
+	// their ETag. DefaultResolverTTL is used if zero.
+	TTL time.Duration
+
+	// MaxDepth limits chains of Object.Resolve calls, such as following
+	// inReplyTo up a thread. DefaultMaxDepth is used if zero.
+	MaxDepth int
+
+	// MaxBodySize is the largest object that is fetched. DefaultMaxBodySize is
+	// used if zero.
+	MaxBodySize int64
+
+	// MaxEntries limits the cache. Expired objects are evicted first, and
+	// then the ones closest to expiring. DefaultResolverMaxEntries is used if
+	// zero.
+	MaxEntries int
+
+	// Now returns the current time. time.Now is used if nil.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*resolverEntry
+}
+
+type resolverEntry struct {
+	url     string
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// Fetch returns the object with the given IRI, from the cache if possible.
+// It fails with ErrOriginMismatch if the object claims an id from another
+// origin than the URL that served it.
+func (r *Resolver) Fetch(ctx context.Context, iri string) (*Object, error) {
+	depth := resolveDepth(ctx)
+	if depth > r.maxDepth() {
+		return nil, xerrors.Errorf("Fetch: %q: %w", iri, ErrMaxDepth)
+	}
+
+	entry := r.cached(iri)
+	if entry == nil || r.now().After(entry.expires) {
+		fetched, err := r.fetch(ctx, iri, entry)
+		if err != nil {
+			return nil, err
+		}
+		entry = fetched
+	}
+
+	obj, err := r.parse(entry.body)
+	if err != nil {
+		return nil, xerrors.Errorf("Fetch: %q: %w", iri, err)
+	}
+	if id := obj.ID(); len(id) > 0 && origin(id) != origin(entry.url) {
+		return nil, xerrors.Errorf("Fetch: %q: id %q served from %q: %w", iri, id, entry.url, ErrOriginMismatch)
+	}
+	obj.depth = depth
+	if obj.fetcher == nil {
+		obj.fetcher = r
+	}
+
+	r.store(iri, entry)
+	return obj, nil
+}
+
+// fetch requests the IRI, revalidating the given cache entry if possible.
+func (r *Resolver) fetch(ctx context.Context, iri string, entry *resolverEntry) (*resolverEntry, error) {
+	req, err := http.NewRequest(http.MethodGet, iri, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("Fetch: %q: %v: %w", iri, err, ErrFetchFailed)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", activityAccept)
+	if entry != nil && len(entry.etag) > 0 {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("Fetch: %q: %v: %w", iri, err, ErrFetchFailed)
+	}
+	defer res.Body.Close()
+
+	expires := r.now().Add(r.ttl())
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		return &resolverEntry{url: entry.url, body: entry.body, etag: entry.etag, expires: expires}, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("Fetch: %q: %s: %w", iri, res.Status, ErrFetchFailed)
+	}
+
+	max := r.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	if res.ContentLength > max {
+		return nil, xerrors.Errorf("Fetch: %q: %d bytes: %w", iri, res.ContentLength, ErrObjectTooLarge)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, max+1))
+	if err != nil {
+		return nil, xerrors.Errorf("Fetch: %q: %v: %w", iri, err, ErrFetchFailed)
+	}
+	if int64(len(body)) > max {
+		return nil, xerrors.Errorf("Fetch: %q: %w", iri, ErrObjectTooLarge)
+	}
+
+	return &resolverEntry{
+		url:     responseURL(res, iri),
+		body:    body,
+		etag:    res.Header.Get("ETag"),
+		expires: expires,
+	}, nil
+}
+
+// responseURL returns the URL that served the response, after redirects.
+func responseURL(res *http.Response, iri string) string {
+	if res.Request != nil && res.Request.URL != nil {
+		return res.Request.URL.String()
+	}
+	return iri
+}
+
+func (r *Resolver) parse(body []byte) (*Object, error) {
+	p := Parser{}
+	if r.Parser != nil {
+		p = *r.Parser
+	}
+	if p.Fetcher == nil {
+		p.Fetcher = r
+	}
+	return p.Parse(bytes.NewReader(body))
+}
+
+func (r *Resolver) cached(iri string) *resolverEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cache[iri]
+}
+
+func (r *Resolver) store(iri string, entry *resolverEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cache == nil {
+		r.cache = make(map[string]*resolverEntry)
+	}
+	if _, ok := r.cache[iri]; !ok {
+		r.evict()
+	}
+	r.cache[iri] = entry
+}
+
+// evict makes room for a new cache entry.
+func (r *Resolver) evict() {
+	max := r.MaxEntries
+	if max <= 0 {
+		max = DefaultResolverMaxEntries
+	}
+	if len(r.cache) < max {
+		return
+	}
+
+	now := r.now()
+	for iri, entry := range r.cache {
+		if now.After(entry.expires) {
+			delete(r.cache, iri)
+		}
+	}
+
+	for len(r.cache) >= max {
+		var oldest string
+		for iri, entry := range r.cache {
+			if len(oldest) == 0 || entry.expires.Before(r.cache[oldest].expires) {
+				oldest = iri
+			}
+		}
+		delete(r.cache, oldest)
+	}
+}
+
+func (r *Resolver) ttl() time.Duration {
+	if r.TTL > 0 {
+		return r.TTL
+	}
+	return DefaultResolverTTL
+}
+
+func (r *Resolver) maxDepth() int {
+	if r.MaxDepth > 0 {
+		return r.MaxDepth
+	}
+	return DefaultMaxDepth
+}
+
+func (r *Resolver) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// Resolve returns the object of the given property. Bare IRIs, which are
+// otherwise returned as objects with only an id, or a Link or Image with only
+// an href or url, are fetched with the Fetcher of the Parser or Resolver that
+// returned this object.
+func (o *Object) Resolve(ctx context.Context, key string) (*Object, error) {
+	obj, err := o.FetchObject(key)
+	if err != nil || obj == nil {
+		return obj, err
+	}
+
+	if !isReference(obj) || len(obj.ID()) == 0 {
+		return obj, nil
+	}
+
+	if o.fetcher == nil {
+		return obj, xerrors.Errorf("Resolve: %s.%s: %w", o.Type(), key, ErrNoFetcher)
+	}
+
+	resolved, err := o.fetcher.Fetch(withResolveDepth(ctx, o.depth+1), obj.ID())
+	if err != nil {
+		return obj, err
+	}
+	if resolved.fetcher == nil {
+		resolved.fetcher = o.fetcher
+	}
+	resolved.depth = o.depth + 1
+	return resolved, nil
+}
+
+// isReference returns true if the object is only an id, or was built from a
+// bare IRI, such as an icon IRI that was returned as an Image.
+func isReference(o *Object) bool {
+	if o.ref {
+		return true
+	}
+	_, ok := o.data["id"].(string)
+	return ok && len(o.data) == 1
+}
+
+type resolveDepthKey struct{}
+
+func withResolveDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, resolveDepthKey{}, depth)
+}
+
+// resolveDepth returns the number of nested Resolve calls that led to a fetch.
+func resolveDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(resolveDepthKey{}).(int)
+	return depth
+}
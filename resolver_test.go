@@ -0,0 +1,189 @@
+package apub_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestResolver(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		assert.True(t, strings.HasPrefix(r.Header.Get("Accept"), "application/activity+json"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		var n int
+		switch _, err := fmt.Sscanf(r.URL.Path, "/note/%d", &n); {
+		case err == nil && n == 1:
+			fmt.Fprintf(w, `{"id": "%s/note/1", "type": "Note", "content": "first"}`, server.URL)
+		case err == nil:
+			fmt.Fprintf(w, `{"id": "%s/note/%d", "type": "Note", "content": "reply", "inReplyTo": "%s/note/%d"}`,
+				server.URL, n, server.URL, n-1)
+		case r.URL.Path == "/alias":
+			fmt.Fprintf(w, `{"id": "%s/note/1", "type": "Note", "content": "first"}`, server.URL)
+		case r.URL.Path == "/large":
+			fmt.Fprintf(w, `{"type": "Note", "content": "%s"}`, strings.Repeat("a", 1024))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolver := &apub.Resolver{
+		TTL:         time.Minute,
+		MaxDepth:    3,
+		MaxBodySize: 512,
+		Now:         func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	t.Run("fetch and cache", func(t *testing.T) {
+		obj, err := resolver.Fetch(ctx, server.URL+"/note/1")
+		require.Nil(t, err)
+		assert.Equal(t, "first", obj.Content(""))
+
+		_, err = resolver.Fetch(ctx, server.URL+"/note/1")
+		require.Nil(t, err)
+		assert.Equal(t, 1, requests["/note/1"])
+
+		now = now.Add(2 * time.Minute)
+		obj, err = resolver.Fetch(ctx, server.URL+"/note/1")
+		require.Nil(t, err)
+		assert.Equal(t, "first", obj.Content(""))
+		assert.Equal(t, 2, requests["/note/1"])
+
+		obj, err = resolver.Fetch(ctx, server.URL+"/alias")
+		require.Nil(t, err)
+		assert.Equal(t, server.URL+"/note/1", obj.ID())
+		assert.Equal(t, 1, requests["/alias"])
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		reply, err := resolver.Fetch(ctx, server.URL+"/note/3")
+		require.Nil(t, err)
+
+		parent, err := reply.Resolve(ctx, "inReplyTo")
+		require.Nil(t, err)
+		assert.Equal(t, server.URL+"/note/2", parent.ID())
+		assert.Equal(t, "reply", parent.Content(""))
+
+		first, err := parent.Resolve(ctx, "inReplyTo")
+		require.Nil(t, err)
+		assert.Equal(t, "first", first.Content(""))
+
+		missing, err := first.Resolve(ctx, "inReplyTo")
+		assert.Nil(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		obj, err := resolver.Fetch(ctx, server.URL+"/note/9")
+		require.Nil(t, err)
+		for i := 0; i < 3; i++ {
+			obj, err = obj.Resolve(ctx, "inReplyTo")
+			require.Nil(t, err)
+		}
+		assert.Equal(t, server.URL+"/note/6", obj.ID())
+
+		stub, err := obj.Resolve(ctx, "inReplyTo")
+		assert.True(t, xerrors.Is(err, apub.ErrMaxDepth), err)
+		assert.Equal(t, server.URL+"/note/5", stub.ID())
+		assert.Equal(t, "", stub.Content(""))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := resolver.Fetch(ctx, server.URL+"/missing")
+		assert.True(t, xerrors.Is(err, apub.ErrFetchFailed), err)
+
+		_, err = resolver.Fetch(ctx, server.URL+"/large")
+		assert.True(t, xerrors.Is(err, apub.ErrObjectTooLarge), err)
+	})
+
+	t.Run("cross-origin id", func(t *testing.T) {
+		evil := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"id": "%s/note/7", "type": "Note", "content": "forged"}`, server.URL)
+		}))
+		defer evil.Close()
+
+		_, err := resolver.Fetch(ctx, evil.URL+"/x")
+		assert.True(t, xerrors.Is(err, apub.ErrOriginMismatch), err)
+
+		// the id is checked against the URL after redirects
+		var redirect *httptest.Server
+		forger := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"id": "%s/note/1", "type": "Note", "content": "forged"}`, redirect.URL)
+		}))
+		defer forger.Close()
+		redirect = httptest.NewServer(http.RedirectHandler(forger.URL+"/x", http.StatusFound))
+		defer redirect.Close()
+		_, err = resolver.Fetch(ctx, redirect.URL+"/note/1")
+		assert.True(t, xerrors.Is(err, apub.ErrOriginMismatch), err)
+
+		obj, err := resolver.Fetch(ctx, server.URL+"/note/7")
+		require.Nil(t, err)
+		assert.Equal(t, "reply", obj.Content(""))
+		assert.Equal(t, 1, requests["/note/7"])
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		now := now
+		resolver := &apub.Resolver{MaxEntries: 2, Now: func() time.Time { return now }}
+		for _, n := range []int{20, 21, 22} {
+			_, err := resolver.Fetch(ctx, fmt.Sprintf("%s/note/%d", server.URL, n))
+			require.Nil(t, err)
+			now = now.Add(time.Second)
+		}
+
+		for _, n := range []int{22, 21, 20} {
+			_, err := resolver.Fetch(ctx, fmt.Sprintf("%s/note/%d", server.URL, n))
+			require.Nil(t, err)
+		}
+		assert.Equal(t, 1, requests["/note/22"])
+		assert.Equal(t, 1, requests["/note/21"])
+		assert.Equal(t, 2, requests["/note/20"])
+	})
+
+	t.Run("parsed objects", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Create",
+			"actor": "https://example.com/~erik",
+			"object": {"id": "https://example.com/note/1", "type": "Note"}
+		}`)
+
+		note, err := obj.Resolve(ctx, "object")
+		assert.Nil(t, err)
+		assert.Equal(t, "Note", note.Type())
+
+		actor, err := obj.Resolve(ctx, "actor")
+		assert.True(t, xerrors.Is(err, apub.ErrNoFetcher), err)
+		assert.Equal(t, "https://example.com/~erik", actor.ID())
+
+		p := &apub.Parser{Fetcher: resolver}
+		obj, err = p.Parse(strings.NewReader(`{"type": "Note", "inReplyTo": "` + server.URL + `/note/1"}`))
+		require.Nil(t, err)
+		parent, err := obj.Resolve(ctx, "inReplyTo")
+		require.Nil(t, err)
+		assert.Equal(t, "first", parent.Content(""))
+	})
+}
@@ -0,0 +1,185 @@
+package apub
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mentions returns the Mention tags, without duplicate hrefs.
+func (o *Object) Mentions() []*Mention {
+	var mentions []*Mention
+	seen := make(map[string]bool)
+	for _, tag := range o.Tags() {
+		if m, ok := AsMention(tag); ok && len(m.Href()) > 0 && !seen[m.Href()] {
+			seen[m.Href()] = true
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// Hashtags returns the Hashtag tags, without duplicate normalized tags.
+func (o *Object) Hashtags() []*Hashtag {
+	var hashtags []*Hashtag
+	seen := make(map[string]bool)
+	for _, tag := range o.Tags() {
+		if h, ok := AsHashtag(tag); ok && len(h.Tag()) > 0 && !seen[h.Tag()] {
+			seen[h.Tag()] = true
+			hashtags = append(hashtags, h)
+		}
+	}
+	return hashtags
+}
+
+// Emojis returns the custom Emoji tags that Mastodon uses, without duplicate
+// shortcodes.
+func (o *Object) Emojis() []*Emoji {
+	var emojis []*Emoji
+	seen := make(map[string]bool)
+	for _, tag := range o.Tags() {
+		if e, ok := AsEmoji(tag); ok && len(e.Shortcode()) > 0 && !seen[e.Shortcode()] {
+			seen[e.Shortcode()] = true
+			emojis = append(emojis, e)
+		}
+	}
+	return emojis
+}
+
+// LinkedTags returns the tags that appear in the content and summary in the
+// given language. Mentions and hashtags must be linked by their href, or by
+// their name as the text of a link. Emoji must have their ":shortcode:" in the
+// content, summary, or name. Tags that are left out include silent mentions,
+// which address actors without mentioning them in the content.
+func (o *Object) LinkedTags(lang string) ([]*Mention, []*Hashtag, []*Emoji) {
+	links := make(map[string]bool)
+	var text strings.Builder
+	for _, s := range []string{o.Content(lang), o.Summary(lang)} {
+		contentLinks(links, s)
+		text.WriteString(HTMLToText(s))
+		text.WriteByte('\n')
+	}
+	text.WriteString(o.Name(lang))
+
+	var mentions []*Mention
+	for _, m := range o.Mentions() {
+		name := m.Str("name")
+		if links[m.Href()] || (len(name) > 0 && (links[name] || links[mentionUser(name)])) {
+			mentions = append(mentions, m)
+		}
+	}
+
+	var hashtags []*Hashtag
+	for _, h := range o.Hashtags() {
+		if links[h.Href()] || links["#"+h.Tag()] {
+			hashtags = append(hashtags, h)
+		}
+	}
+
+	var emojis []*Emoji
+	for _, e := range o.Emojis() {
+		if strings.Contains(text.String(), ":"+e.Shortcode()+":") {
+			emojis = append(emojis, e)
+		}
+	}
+	return mentions, hashtags, emojis
+}
+
+// Tag returns the normalized hashtag, without the "#" and case folded, so that
+// "#ActivityPub" and "#activitypub" match. The last segment of the href is
+// used if the hashtag has no name.
+func (h *Hashtag) Tag() string {
+	name := h.Str("name")
+	if len(name) == 0 {
+		if u, err := url.Parse(h.Href()); err == nil && len(u.Path) > 0 {
+			name = path.Base(u.Path)
+		}
+	}
+	return normalizeHashtag(name)
+}
+
+// Shortcode returns the name of the emoji without its colons, such as
+// "blobcat" for ":blobcat:".
+func (e *Emoji) Shortcode() string {
+	return strings.Trim(e.Str("name"), ":")
+}
+
+// IconURL returns the url of the emoji's icon image.
+func (e *Emoji) IconURL() string {
+	for _, icon := range e.Icons() {
+		for _, u := range icon.URLs() {
+			if href := u.DefaultValue(); len(href) > 0 {
+				return href
+			}
+		}
+	}
+	return ""
+}
+
+func normalizeHashtag(name string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
+	return norm.NFKC.String(cases.Fold().String(name))
+}
+
+// mentionUser returns "@user" from a mention name like "@user@example.com".
+func mentionUser(name string) string {
+	if i := strings.LastIndexByte(name, '@'); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// contentLinks adds the href and text of each link in the HTML. Link text
+// starting with "#" is added as a normalized hashtag.
+func contentLinks(links map[string]bool, s string) {
+	if len(s) == 0 {
+		return
+	}
+	nodes, err := parseHTMLFragment(s)
+	if err != nil {
+		return
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			for _, attr := range n.Attr {
+				if attr.Key == "href" {
+					links[attr.Val] = true
+				}
+			}
+
+			text := strings.TrimSpace(nodeText(n))
+			if strings.HasPrefix(text, "#") {
+				text = "#" + normalizeHashtag(text)
+			}
+			if len(text) > 0 {
+				links[text] = true
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+// nodeText returns the text in the node and its children.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var text strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		text.WriteString(nodeText(c))
+	}
+	return text.String()
+}
@@ -0,0 +1,113 @@
+package apub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/technoweenie/apub"
+)
+
+func TestTags(t *testing.T) {
+	obj := Parse(t, `{
+		"type": "Note",
+		"attributedTo": "https://mastodon.gamedev.place/users/bob",
+		"content": "<p><span class=\"h-card\"><a href=\"https://mastodon.social/@Gargron\" class=\"u-url mention\">@<span>Gargron</span></a></span> <a href=\"https://pixelfed.social/carol\">@carol</a> loves <a href=\"https://mastodon.gamedev.place/tags/ActivityPub\" class=\"mention hashtag\" rel=\"tag\">#<span>ActivityPub</span></a> and <a href=\"https://example.com/tag/Straße\">#STRASSE</a> :blobcat:</p>",
+		"tag": [
+			{"type": "Mention", "href": "https://mastodon.social/users/Gargron", "name": "@Gargron@mastodon.social"},
+			{"type": "Mention", "href": "https://pixelfed.social/carol", "name": "@carol@pixelfed.social"},
+			{"type": "Mention", "href": "https://mastodon.social/users/Gargron", "name": "@Gargron"},
+			{"type": "Mention", "href": "https://example.com/~silent", "name": "@silent@example.com"},
+			{"type": "Hashtag", "href": "https://mastodon.gamedev.place/tags/activitypub", "name": "#activitypub"},
+			{"type": "Hashtag", "href": "https://mastodon.gamedev.place/tags/ActivityPub", "name": "#ActivityPub"},
+			{"type": "Hashtag", "href": "https://example.com/tag/stra%C3%9Fe"},
+			{"type": "Hashtag", "href": "https://mastodon.gamedev.place/tags/unused", "name": "#unused"},
+			{
+				"id": "https://mastodon.gamedev.place/emojis/1",
+				"type": "Emoji",
+				"name": ":blobcat:",
+				"updated": "2020-05-01T12:00:00Z",
+				"icon": {
+					"type": "Image",
+					"mediaType": "image/png",
+					"url": "https://mastodon.gamedev.place/system/custom_emojis/blobcat.png"
+				}
+			},
+			{
+				"type": "Emoji",
+				"name": ":unused:",
+				"icon": {"type": "Image", "url": {"type": "Link", "href": "https://example.com/unused.png"}}
+			},
+			{"type": "Person", "id": "https://example.com/~erik"}
+		]
+	}`)
+
+	t.Run("mentions", func(t *testing.T) {
+		mentions := obj.Mentions()
+		var pairs [][2]string
+		for _, m := range mentions {
+			pairs = append(pairs, [2]string{m.Href(), m.Str("name")})
+		}
+		assert.Equal(t, [][2]string{
+			{"https://mastodon.social/users/Gargron", "@Gargron@mastodon.social"},
+			{"https://pixelfed.social/carol", "@carol@pixelfed.social"},
+			{"https://example.com/~silent", "@silent@example.com"},
+		}, pairs)
+	})
+
+	t.Run("hashtags", func(t *testing.T) {
+		var tags []string
+		for _, h := range obj.Hashtags() {
+			tags = append(tags, h.Tag())
+		}
+		assert.Equal(t, []string{"activitypub", "strasse", "unused"}, tags)
+	})
+
+	t.Run("emojis", func(t *testing.T) {
+		emojis := obj.Emojis()
+		if assert.Equal(t, 2, len(emojis)) {
+			assert.Equal(t, "blobcat", emojis[0].Shortcode())
+			assert.Equal(t, "https://mastodon.gamedev.place/system/custom_emojis/blobcat.png", emojis[0].IconURL())
+			assert.Equal(t, "unused", emojis[1].Shortcode())
+			assert.Equal(t, "https://example.com/unused.png", emojis[1].IconURL())
+		}
+	})
+
+	t.Run("linked", func(t *testing.T) {
+		mentions, hashtags, emojis := obj.LinkedTags("")
+
+		var hrefs []string
+		for _, m := range mentions {
+			hrefs = append(hrefs, m.Href())
+		}
+		assert.Equal(t, []string{
+			"https://mastodon.social/users/Gargron",
+			"https://pixelfed.social/carol",
+		}, hrefs)
+
+		var tags []string
+		for _, h := range hashtags {
+			tags = append(tags, h.Tag())
+		}
+		assert.Equal(t, []string{"activitypub", "strasse"}, tags)
+
+		if assert.Equal(t, 1, len(emojis)) {
+			assert.Equal(t, "blobcat", emojis[0].Shortcode())
+		}
+	})
+
+	t.Run("actor emoji", func(t *testing.T) {
+		actor := Parse(t, `{
+			"type": "Person",
+			"name": "Bob :verified:",
+			"tag": [{"type": "Emoji", "name": ":verified:", "icon": {"type": "Image", "url": "https://example.com/v.png"}}]
+		}`)
+		_, _, emojis := actor.LinkedTags("")
+		if assert.Equal(t, 1, len(emojis)) {
+			assert.Equal(t, "https://example.com/v.png", emojis[0].IconURL())
+		}
+	})
+
+	assert.Equal(t, 0, len(Parse(t, `{"type": "Note"}`).Mentions()))
+	_, ok := apub.AsHashtag(obj)
+	assert.False(t, ok)
+}
@@ -0,0 +1,239 @@
+package apub
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ContentText returns the content property as plain text.
+func (o *Object) ContentText(lang string) string {
+	return HTMLToText(o.Content(lang))
+}
+
+// SummaryText returns the summary property as plain text.
+func (o *Object) SummaryText(lang string) string {
+	return HTMLToText(o.Summary(lang))
+}
+
+// HTMLToText converts an HTML fragment to plain text, with entities decoded.
+// Paragraphs are separated by blank lines, and br elements by newlines. Links
+// are replaced by their text, or their href if they have none, so mentions and
+// hashtags become "@user" and "#tag". Whitespace is collapsed outside of pre
+// elements.
+func HTMLToText(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+
+	nodes, err := parseHTMLFragment(s)
+	if err != nil {
+		return s
+	}
+
+	t := &textRenderer{}
+	for _, n := range nodes {
+		t.render(n)
+	}
+	return t.buf.String()
+}
+
+type textRenderer struct {
+	buf      strings.Builder
+	newlines int
+	space    bool
+	pre      int
+}
+
+func (t *textRenderer) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		t.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch {
+	case droppedElements[n.DataAtom]:
+		return
+	case n.DataAtom == atom.Br:
+		t.newlines++
+		return
+	case n.DataAtom == atom.A && !hasText(n):
+		for _, attr := range n.Attr {
+			if attr.Key == "href" {
+				t.text(attr.Val)
+			}
+		}
+		return
+	}
+
+	block := textBlocks[n.DataAtom]
+	t.breakLine(block)
+	if n.DataAtom == atom.Pre {
+		t.pre++
+		defer func() { t.pre-- }()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		t.render(c)
+	}
+	t.breakLine(block)
+}
+
+// breakLine separates blocks by at least the given number of newlines.
+func (t *textRenderer) breakLine(n int) {
+	if n > t.newlines {
+		t.newlines = n
+	}
+}
+
+func (t *textRenderer) text(s string) {
+	if t.pre > 0 {
+		t.write(s)
+		return
+	}
+
+	for i, word := range strings.Fields(s) {
+		if i > 0 || startsWithSpace(s) {
+			t.space = true
+		}
+		t.write(word)
+	}
+	if endsWithSpace(s) {
+		t.space = true
+	}
+}
+
+// write adds the text after any pending line breaks or space. Spaces at the
+// start and end of lines are dropped.
+func (t *textRenderer) write(s string) {
+	if len(s) == 0 {
+		return
+	}
+	if t.buf.Len() > 0 {
+		if t.newlines > 0 {
+			t.buf.WriteString(strings.Repeat("\n", t.newlines))
+		} else if t.space {
+			t.buf.WriteByte(' ')
+		}
+	}
+	t.newlines = 0
+	t.space = false
+	t.buf.WriteString(s)
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+// hasText returns true if the element contains any text that isn't
+// whitespace.
+func hasText(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode && len(strings.TrimSpace(c.Data)) > 0 {
+			return true
+		}
+		if c.Type == html.ElementNode && hasText(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// textBlocks are the elements that start new lines, and the number of
+// newlines that separate them from their siblings.
+var textBlocks = map[atom.Atom]int{
+	atom.Blockquote: 2,
+	atom.Div:        1,
+	atom.H1:         2,
+	atom.H2:         2,
+	atom.H3:         2,
+	atom.H4:         2,
+	atom.H5:         2,
+	atom.H6:         2,
+	atom.Li:         1,
+	atom.Ol:         2,
+	atom.P:          2,
+	atom.Pre:        2,
+	atom.Ul:         2,
+}
+
+// Excerpt truncates plain text to at most max user-perceived characters,
+// ending with "…" if it was cut. Combining marks, emoji with modifiers or ZWJ
+// sequences, and flags are never split.
+func Excerpt(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+
+	keep := 0
+	for i, n := 0, 0; i < len(s); n++ {
+		if n == max-1 {
+			keep = i
+		}
+		if n == max {
+			return strings.TrimRightFunc(s[:keep], unicode.IsSpace) + "…"
+		}
+		i += nextGrapheme(s[i:])
+	}
+	return s
+}
+
+// nextGrapheme returns the length of the first grapheme cluster in s. It is a
+// simplified version of the rules in Unicode Standard Annex #29, that covers
+// combining marks, emoji sequences, and flags.
+func nextGrapheme(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == '\r' && strings.HasPrefix(s[size:], "\n") {
+		return size + 1
+	}
+	if isRegionalIndicator(r) {
+		if next, n := utf8.DecodeRuneInString(s[size:]); isRegionalIndicator(next) {
+			return size + n
+		}
+		return size
+	}
+
+	for size < len(s) {
+		next, n := utf8.DecodeRuneInString(s[size:])
+		switch {
+		case next == zeroWidthJoiner:
+			size += n
+			if size < len(s) {
+				_, n = utf8.DecodeRuneInString(s[size:])
+				size += n
+			}
+		case isGraphemeExtend(next):
+			size += n
+		default:
+			return size
+		}
+	}
+	return size
+}
+
+const zeroWidthJoiner = '\u200d'
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isGraphemeExtend returns true for runes that extend the previous character,
+// such as combining accents, variation selectors, skin tone modifiers, and
+// the tags in subdivision flags.
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0xe0020 && r <= 0xe007f)
+}
@@ -0,0 +1,99 @@
+package apub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/technoweenie/apub"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "paragraphs",
+			input:    "<p>First  paragraph,\n with a <em>note</em>.</p><p>Second<br>line<br/><br>after a gap</p>",
+			expected: "First paragraph, with a note.\n\nSecond\nline\n\nafter a gap",
+		},
+		{
+			name:     "mentions and hashtags",
+			input:    `<p><span class="h-card"><a href="https://mastodon.gamedev.place/@bob" class="u-url mention">@<span>bob</span></a></span> check out <a href="https://example.com/tags/go" class="mention hashtag" rel="tag">#<span>go</span></a></p>`,
+			expected: "@bob check out #go",
+		},
+		{
+			name:     "links",
+			input:    `<p>see <a href="https://example.com/a/long/path"><span class="invisible">https://</span><span class="ellipsis">example.com/a/lo</span><span class="invisible">ng/path</span></a> and <a href="https://example.com/empty"></a></p>`,
+			expected: "see https://example.com/a/long/path and https://example.com/empty",
+		},
+		{
+			name:     "entities",
+			input:    `1 &lt; 2 &amp;&amp; &quot;caf&eacute;&quot; &#x1F600;`,
+			expected: `1 < 2 && "café" 😀`,
+		},
+		{
+			name:     "lists and quotes",
+			input:    `<p>list:</p><ul><li>one</li><li>two</li></ul><blockquote>quoted</blockquote>`,
+			expected: "list:\n\none\ntwo\n\nquoted",
+		},
+		{
+			name:     "pre",
+			input:    "<p>code:</p><pre>func main() {\n\tfmt.Println(\"hi\")\n}</pre>",
+			expected: "code:\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}",
+		},
+		{
+			name:     "scripts",
+			input:    `<p>hi<script>alert(1)</script></p>`,
+			expected: "hi",
+		},
+		{
+			name:     "plain text",
+			input:    "  just text  ",
+			expected: "just text",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, apub.HTMLToText(test.input))
+		})
+	}
+
+	t.Run("accessors", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Note",
+			"summary": "<p>CW: <em>spoilers</em></p>",
+			"content": "<p>A simple <em>note</em></p>",
+			"contentMap": {"es": "<p>Una <em>nota</em> sencilla</p>"}
+		}`)
+		assert.Equal(t, "CW: spoilers", obj.SummaryText(""))
+		assert.Equal(t, "A simple note", obj.ContentText(""))
+		assert.Equal(t, "Una nota sencilla", obj.ContentText("es"))
+	})
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		input    string
+		max      int
+		expected string
+	}{
+		{"hello world", 20, "hello world"},
+		{"hello world", 11, "hello world"},
+		{"hello world", 7, "hello…"},
+		{"hello world", 1, "…"},
+		{"hello world", 0, ""},
+		{"café au lait", 5, "café…"},
+		{"👍🏽👍🏽👍🏽", 2, "👍🏽…"},
+		{"👨‍👩‍👧‍👦 family", 2, "👨‍👩‍👧‍👦…"},
+		{"🇨🇦🇺🇸🇲🇽", 3, "🇨🇦🇺🇸🇲🇽"},
+		{"🇨🇦🇺🇸🇲🇽", 2, "🇨🇦…"},
+		{"line\r\nbreak", 7, "line\r\nb…"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, apub.Excerpt(test.input, test.max), "%q %d", test.input, test.max)
+	}
+}
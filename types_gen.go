@@ -0,0 +1,1177 @@
+// Code generated by gen_vocabulary.go; DO NOT EDIT.
+
+package apub
+
+import "time"
+
+// Context returns the context property.
+func (o *Object) Context() *Object {
+	return o.Object("context")
+}
+
+// Duration returns the duration property.
+func (o *Object) Duration() string {
+	return o.Str("duration")
+}
+
+// EndTime returns the endTime property.
+func (o *Object) EndTime() time.Time {
+	return o.Time("endTime")
+}
+
+// Generators returns the generator property.
+func (o *Object) Generators() []*Object {
+	return o.List("generator")
+}
+
+// InReplyTo returns the inReplyTo property.
+func (o *Object) InReplyTo() []string {
+	return o.IDs("inReplyTo")
+}
+
+// Locations returns the location property.
+func (o *Object) Locations() []*Object {
+	return o.List("location")
+}
+
+// MediaType returns the mediaType property.
+func (o *Object) MediaType() string {
+	return o.Str("mediaType")
+}
+
+// Previews returns the preview property.
+func (o *Object) Previews() []*Object {
+	return o.List("preview")
+}
+
+// Published returns the published property.
+func (o *Object) Published() time.Time {
+	return o.Time("published")
+}
+
+// Replies returns the replies property.
+func (o *Object) Replies() *Object {
+	return o.Object("replies")
+}
+
+// StartTime returns the startTime property.
+func (o *Object) StartTime() time.Time {
+	return o.Time("startTime")
+}
+
+// Updated returns the updated property.
+func (o *Object) Updated() time.Time {
+	return o.Time("updated")
+}
+
+// Likes returns the likes property.
+func (o *Object) Likes() *Object {
+	return o.Object("likes")
+}
+
+// Shares returns the shares property.
+func (o *Object) Shares() *Object {
+	return o.Object("shares")
+}
+
+// Source returns the source property.
+func (o *Object) Source() *Object {
+	return o.Object("source")
+}
+
+// AlsoKnownAs returns the alsoKnownAs property.
+func (o *Object) AlsoKnownAs() []string {
+	return o.IDs("alsoKnownAs")
+}
+
+// Sensitive returns the sensitive property.
+func (o *Object) Sensitive() bool {
+	return o.Bool("sensitive")
+}
+
+// Link is a typed view of an ActivityStreams Link.
+type Link struct {
+	*Object
+}
+
+// AsLink returns a view of the object if its type is Link, or a
+// type that extends it.
+func AsLink(o *Object) (*Link, bool) {
+	if o == nil || !o.IsType("Link") {
+		return nil, false
+	}
+	return &Link{o}, true
+}
+
+// Height returns the height property.
+func (v *Link) Height() int {
+	return v.Object.Int("height")
+}
+
+// Href returns the href property.
+func (v *Link) Href() string {
+	return v.Object.Str("href")
+}
+
+// Hreflang returns the hreflang property.
+func (v *Link) Hreflang() string {
+	return v.Object.Str("hreflang")
+}
+
+// Rel returns the rel property.
+func (v *Link) Rel() []string {
+	return v.Object.IDs("rel")
+}
+
+// Width returns the width property.
+func (v *Link) Width() int {
+	return v.Object.Int("width")
+}
+
+// Actor is a typed view of an ActivityStreams Actor.
+type Actor struct {
+	*Object
+}
+
+// AsActor returns a view of the object if its type is Actor, or a
+// type that extends it.
+func AsActor(o *Object) (*Actor, bool) {
+	if o == nil || !o.IsType("Actor") {
+		return nil, false
+	}
+	return &Actor{o}, true
+}
+
+// Endpoints returns the endpoints property.
+func (v *Actor) Endpoints() *Object {
+	return v.Object.Object("endpoints")
+}
+
+// Followers returns the followers property.
+func (v *Actor) Followers() string {
+	return v.Object.Str("followers")
+}
+
+// Following returns the following property.
+func (v *Actor) Following() string {
+	return v.Object.Str("following")
+}
+
+// Inbox returns the inbox property.
+func (v *Actor) Inbox() string {
+	return v.Object.Str("inbox")
+}
+
+// Liked returns the liked property.
+func (v *Actor) Liked() string {
+	return v.Object.Str("liked")
+}
+
+// Outbox returns the outbox property.
+func (v *Actor) Outbox() string {
+	return v.Object.Str("outbox")
+}
+
+// PreferredUsername returns the preferredUsername property.
+func (v *Actor) PreferredUsername() string {
+	return v.Object.Str("preferredUsername")
+}
+
+// Streams returns the streams property.
+func (v *Actor) Streams() []*Object {
+	return v.Object.List("streams")
+}
+
+// Discoverable returns the discoverable property.
+func (v *Actor) Discoverable() bool {
+	return v.Object.Bool("discoverable")
+}
+
+// Featured returns the featured property.
+func (v *Actor) Featured() string {
+	return v.Object.Str("featured")
+}
+
+// FeaturedTags returns the featuredTags property.
+func (v *Actor) FeaturedTags() string {
+	return v.Object.Str("featuredTags")
+}
+
+// ManuallyApprovesFollowers returns the manuallyApprovesFollowers property.
+func (v *Actor) ManuallyApprovesFollowers() bool {
+	return v.Object.Bool("manuallyApprovesFollowers")
+}
+
+// MovedTo returns the movedTo property.
+func (v *Actor) MovedTo() string {
+	return v.Object.Str("movedTo")
+}
+
+// PublicKey returns the publicKey property.
+func (v *Actor) PublicKey() *Object {
+	return v.Object.Object("publicKey")
+}
+
+// Activity is a typed view of an ActivityStreams Activity.
+type Activity struct {
+	*Object
+}
+
+// AsActivity returns a view of the object if its type is Activity, or a
+// type that extends it.
+func AsActivity(o *Object) (*Activity, bool) {
+	if o == nil || !o.IsType("Activity") {
+		return nil, false
+	}
+	return &Activity{o}, true
+}
+
+// Actor returns the actor property.
+func (v *Activity) Actor() []string {
+	return v.Object.IDs("actor")
+}
+
+// Instruments returns the instrument property.
+func (v *Activity) Instruments() []*Object {
+	return v.Object.List("instrument")
+}
+
+// Objects returns the object property.
+func (v *Activity) Objects() []*Object {
+	return v.Object.List("object")
+}
+
+// Origins returns the origin property.
+func (v *Activity) Origins() []*Object {
+	return v.Object.List("origin")
+}
+
+// Results returns the result property.
+func (v *Activity) Results() []*Object {
+	return v.Object.List("result")
+}
+
+// Targets returns the target property.
+func (v *Activity) Targets() []*Object {
+	return v.Object.List("target")
+}
+
+// IntransitiveActivity is a typed view of an ActivityStreams IntransitiveActivity.
+type IntransitiveActivity struct {
+	*Activity
+}
+
+// AsIntransitiveActivity returns a view of the object if its type is IntransitiveActivity, or a
+// type that extends it.
+func AsIntransitiveActivity(o *Object) (*IntransitiveActivity, bool) {
+	if o == nil || !o.IsType("IntransitiveActivity") {
+		return nil, false
+	}
+	return &IntransitiveActivity{&Activity{o}}, true
+}
+
+// Collection is a typed view of an ActivityStreams Collection.
+type Collection struct {
+	*Object
+}
+
+// AsCollection returns a view of the object if its type is Collection, or a
+// type that extends it.
+func AsCollection(o *Object) (*Collection, bool) {
+	if o == nil || !o.IsType("Collection") {
+		return nil, false
+	}
+	return &Collection{o}, true
+}
+
+// Current returns the current property.
+func (v *Collection) Current() *Object {
+	return v.Object.Object("current")
+}
+
+// First returns the first property.
+func (v *Collection) First() *Object {
+	return v.Object.Object("first")
+}
+
+// Items returns the items property.
+func (v *Collection) Items() []*Object {
+	return v.Object.List("items")
+}
+
+// Last returns the last property.
+func (v *Collection) Last() *Object {
+	return v.Object.Object("last")
+}
+
+// TotalItems returns the totalItems property.
+func (v *Collection) TotalItems() int {
+	return v.Object.Int("totalItems")
+}
+
+// OrderedCollection is a typed view of an ActivityStreams OrderedCollection.
+type OrderedCollection struct {
+	*Collection
+}
+
+// AsOrderedCollection returns a view of the object if its type is OrderedCollection, or a
+// type that extends it.
+func AsOrderedCollection(o *Object) (*OrderedCollection, bool) {
+	if o == nil || !o.IsType("OrderedCollection") {
+		return nil, false
+	}
+	return &OrderedCollection{&Collection{o}}, true
+}
+
+// OrderedItems returns the orderedItems property.
+func (v *OrderedCollection) OrderedItems() []*Object {
+	return v.Object.List("orderedItems")
+}
+
+// CollectionPage is a typed view of an ActivityStreams CollectionPage.
+type CollectionPage struct {
+	*Collection
+}
+
+// AsCollectionPage returns a view of the object if its type is CollectionPage, or a
+// type that extends it.
+func AsCollectionPage(o *Object) (*CollectionPage, bool) {
+	if o == nil || !o.IsType("CollectionPage") {
+		return nil, false
+	}
+	return &CollectionPage{&Collection{o}}, true
+}
+
+// Next returns the next property.
+func (v *CollectionPage) Next() *Object {
+	return v.Object.Object("next")
+}
+
+// PartOf returns the partOf property.
+func (v *CollectionPage) PartOf() *Object {
+	return v.Object.Object("partOf")
+}
+
+// Prev returns the prev property.
+func (v *CollectionPage) Prev() *Object {
+	return v.Object.Object("prev")
+}
+
+// OrderedCollectionPage is a typed view of an ActivityStreams OrderedCollectionPage.
+type OrderedCollectionPage struct {
+	*OrderedCollection
+}
+
+// AsOrderedCollectionPage returns a view of the object if its type is OrderedCollectionPage, or a
+// type that extends it.
+func AsOrderedCollectionPage(o *Object) (*OrderedCollectionPage, bool) {
+	if o == nil || !o.IsType("OrderedCollectionPage") {
+		return nil, false
+	}
+	return &OrderedCollectionPage{&OrderedCollection{&Collection{o}}}, true
+}
+
+// Next returns the next property.
+func (v *OrderedCollectionPage) Next() *Object {
+	return v.Object.Object("next")
+}
+
+// PartOf returns the partOf property.
+func (v *OrderedCollectionPage) PartOf() *Object {
+	return v.Object.Object("partOf")
+}
+
+// Prev returns the prev property.
+func (v *OrderedCollectionPage) Prev() *Object {
+	return v.Object.Object("prev")
+}
+
+// StartIndex returns the startIndex property.
+func (v *OrderedCollectionPage) StartIndex() int {
+	return v.Object.Int("startIndex")
+}
+
+// Accept is a typed view of an ActivityStreams Accept.
+type Accept struct {
+	*Activity
+}
+
+// AsAccept returns a view of the object if its type is Accept, or a
+// type that extends it.
+func AsAccept(o *Object) (*Accept, bool) {
+	if o == nil || !o.IsType("Accept") {
+		return nil, false
+	}
+	return &Accept{&Activity{o}}, true
+}
+
+// Add is a typed view of an ActivityStreams Add.
+type Add struct {
+	*Activity
+}
+
+// AsAdd returns a view of the object if its type is Add, or a
+// type that extends it.
+func AsAdd(o *Object) (*Add, bool) {
+	if o == nil || !o.IsType("Add") {
+		return nil, false
+	}
+	return &Add{&Activity{o}}, true
+}
+
+// Announce is a typed view of an ActivityStreams Announce.
+type Announce struct {
+	*Activity
+}
+
+// AsAnnounce returns a view of the object if its type is Announce, or a
+// type that extends it.
+func AsAnnounce(o *Object) (*Announce, bool) {
+	if o == nil || !o.IsType("Announce") {
+		return nil, false
+	}
+	return &Announce{&Activity{o}}, true
+}
+
+// Arrive is a typed view of an ActivityStreams Arrive.
+type Arrive struct {
+	*IntransitiveActivity
+}
+
+// AsArrive returns a view of the object if its type is Arrive, or a
+// type that extends it.
+func AsArrive(o *Object) (*Arrive, bool) {
+	if o == nil || !o.IsType("Arrive") {
+		return nil, false
+	}
+	return &Arrive{&IntransitiveActivity{&Activity{o}}}, true
+}
+
+// Block is a typed view of an ActivityStreams Block.
+type Block struct {
+	*Ignore
+}
+
+// AsBlock returns a view of the object if its type is Block, or a
+// type that extends it.
+func AsBlock(o *Object) (*Block, bool) {
+	if o == nil || !o.IsType("Block") {
+		return nil, false
+	}
+	return &Block{&Ignore{&Activity{o}}}, true
+}
+
+// Create is a typed view of an ActivityStreams Create.
+type Create struct {
+	*Activity
+}
+
+// AsCreate returns a view of the object if its type is Create, or a
+// type that extends it.
+func AsCreate(o *Object) (*Create, bool) {
+	if o == nil || !o.IsType("Create") {
+		return nil, false
+	}
+	return &Create{&Activity{o}}, true
+}
+
+// Delete is a typed view of an ActivityStreams Delete.
+type Delete struct {
+	*Activity
+}
+
+// AsDelete returns a view of the object if its type is Delete, or a
+// type that extends it.
+func AsDelete(o *Object) (*Delete, bool) {
+	if o == nil || !o.IsType("Delete") {
+		return nil, false
+	}
+	return &Delete{&Activity{o}}, true
+}
+
+// Dislike is a typed view of an ActivityStreams Dislike.
+type Dislike struct {
+	*Activity
+}
+
+// AsDislike returns a view of the object if its type is Dislike, or a
+// type that extends it.
+func AsDislike(o *Object) (*Dislike, bool) {
+	if o == nil || !o.IsType("Dislike") {
+		return nil, false
+	}
+	return &Dislike{&Activity{o}}, true
+}
+
+// Flag is a typed view of an ActivityStreams Flag.
+type Flag struct {
+	*Activity
+}
+
+// AsFlag returns a view of the object if its type is Flag, or a
+// type that extends it.
+func AsFlag(o *Object) (*Flag, bool) {
+	if o == nil || !o.IsType("Flag") {
+		return nil, false
+	}
+	return &Flag{&Activity{o}}, true
+}
+
+// Follow is a typed view of an ActivityStreams Follow.
+type Follow struct {
+	*Activity
+}
+
+// AsFollow returns a view of the object if its type is Follow, or a
+// type that extends it.
+func AsFollow(o *Object) (*Follow, bool) {
+	if o == nil || !o.IsType("Follow") {
+		return nil, false
+	}
+	return &Follow{&Activity{o}}, true
+}
+
+// Ignore is a typed view of an ActivityStreams Ignore.
+type Ignore struct {
+	*Activity
+}
+
+// AsIgnore returns a view of the object if its type is Ignore, or a
+// type that extends it.
+func AsIgnore(o *Object) (*Ignore, bool) {
+	if o == nil || !o.IsType("Ignore") {
+		return nil, false
+	}
+	return &Ignore{&Activity{o}}, true
+}
+
+// Invite is a typed view of an ActivityStreams Invite.
+type Invite struct {
+	*Offer
+}
+
+// AsInvite returns a view of the object if its type is Invite, or a
+// type that extends it.
+func AsInvite(o *Object) (*Invite, bool) {
+	if o == nil || !o.IsType("Invite") {
+		return nil, false
+	}
+	return &Invite{&Offer{&Activity{o}}}, true
+}
+
+// Join is a typed view of an ActivityStreams Join.
+type Join struct {
+	*Activity
+}
+
+// AsJoin returns a view of the object if its type is Join, or a
+// type that extends it.
+func AsJoin(o *Object) (*Join, bool) {
+	if o == nil || !o.IsType("Join") {
+		return nil, false
+	}
+	return &Join{&Activity{o}}, true
+}
+
+// Leave is a typed view of an ActivityStreams Leave.
+type Leave struct {
+	*Activity
+}
+
+// AsLeave returns a view of the object if its type is Leave, or a
+// type that extends it.
+func AsLeave(o *Object) (*Leave, bool) {
+	if o == nil || !o.IsType("Leave") {
+		return nil, false
+	}
+	return &Leave{&Activity{o}}, true
+}
+
+// Like is a typed view of an ActivityStreams Like.
+type Like struct {
+	*Activity
+}
+
+// AsLike returns a view of the object if its type is Like, or a
+// type that extends it.
+func AsLike(o *Object) (*Like, bool) {
+	if o == nil || !o.IsType("Like") {
+		return nil, false
+	}
+	return &Like{&Activity{o}}, true
+}
+
+// Listen is a typed view of an ActivityStreams Listen.
+type Listen struct {
+	*Activity
+}
+
+// AsListen returns a view of the object if its type is Listen, or a
+// type that extends it.
+func AsListen(o *Object) (*Listen, bool) {
+	if o == nil || !o.IsType("Listen") {
+		return nil, false
+	}
+	return &Listen{&Activity{o}}, true
+}
+
+// Move is a typed view of an ActivityStreams Move.
+type Move struct {
+	*Activity
+}
+
+// AsMove returns a view of the object if its type is Move, or a
+// type that extends it.
+func AsMove(o *Object) (*Move, bool) {
+	if o == nil || !o.IsType("Move") {
+		return nil, false
+	}
+	return &Move{&Activity{o}}, true
+}
+
+// Offer is a typed view of an ActivityStreams Offer.
+type Offer struct {
+	*Activity
+}
+
+// AsOffer returns a view of the object if its type is Offer, or a
+// type that extends it.
+func AsOffer(o *Object) (*Offer, bool) {
+	if o == nil || !o.IsType("Offer") {
+		return nil, false
+	}
+	return &Offer{&Activity{o}}, true
+}
+
+// Question is a typed view of an ActivityStreams Question.
+type Question struct {
+	*IntransitiveActivity
+}
+
+// AsQuestion returns a view of the object if its type is Question, or a
+// type that extends it.
+func AsQuestion(o *Object) (*Question, bool) {
+	if o == nil || !o.IsType("Question") {
+		return nil, false
+	}
+	return &Question{&IntransitiveActivity{&Activity{o}}}, true
+}
+
+// AnyOf returns the anyOf property.
+func (v *Question) AnyOf() []*Object {
+	return v.Object.List("anyOf")
+}
+
+// Closed returns the closed property.
+func (v *Question) Closed() string {
+	return v.Object.Str("closed")
+}
+
+// OneOf returns the oneOf property.
+func (v *Question) OneOf() []*Object {
+	return v.Object.List("oneOf")
+}
+
+// Read is a typed view of an ActivityStreams Read.
+type Read struct {
+	*Activity
+}
+
+// AsRead returns a view of the object if its type is Read, or a
+// type that extends it.
+func AsRead(o *Object) (*Read, bool) {
+	if o == nil || !o.IsType("Read") {
+		return nil, false
+	}
+	return &Read{&Activity{o}}, true
+}
+
+// Reject is a typed view of an ActivityStreams Reject.
+type Reject struct {
+	*Activity
+}
+
+// AsReject returns a view of the object if its type is Reject, or a
+// type that extends it.
+func AsReject(o *Object) (*Reject, bool) {
+	if o == nil || !o.IsType("Reject") {
+		return nil, false
+	}
+	return &Reject{&Activity{o}}, true
+}
+
+// Remove is a typed view of an ActivityStreams Remove.
+type Remove struct {
+	*Activity
+}
+
+// AsRemove returns a view of the object if its type is Remove, or a
+// type that extends it.
+func AsRemove(o *Object) (*Remove, bool) {
+	if o == nil || !o.IsType("Remove") {
+		return nil, false
+	}
+	return &Remove{&Activity{o}}, true
+}
+
+// TentativeAccept is a typed view of an ActivityStreams TentativeAccept.
+type TentativeAccept struct {
+	*Accept
+}
+
+// AsTentativeAccept returns a view of the object if its type is TentativeAccept, or a
+// type that extends it.
+func AsTentativeAccept(o *Object) (*TentativeAccept, bool) {
+	if o == nil || !o.IsType("TentativeAccept") {
+		return nil, false
+	}
+	return &TentativeAccept{&Accept{&Activity{o}}}, true
+}
+
+// TentativeReject is a typed view of an ActivityStreams TentativeReject.
+type TentativeReject struct {
+	*Reject
+}
+
+// AsTentativeReject returns a view of the object if its type is TentativeReject, or a
+// type that extends it.
+func AsTentativeReject(o *Object) (*TentativeReject, bool) {
+	if o == nil || !o.IsType("TentativeReject") {
+		return nil, false
+	}
+	return &TentativeReject{&Reject{&Activity{o}}}, true
+}
+
+// Travel is a typed view of an ActivityStreams Travel.
+type Travel struct {
+	*IntransitiveActivity
+}
+
+// AsTravel returns a view of the object if its type is Travel, or a
+// type that extends it.
+func AsTravel(o *Object) (*Travel, bool) {
+	if o == nil || !o.IsType("Travel") {
+		return nil, false
+	}
+	return &Travel{&IntransitiveActivity{&Activity{o}}}, true
+}
+
+// Undo is a typed view of an ActivityStreams Undo.
+type Undo struct {
+	*Activity
+}
+
+// AsUndo returns a view of the object if its type is Undo, or a
+// type that extends it.
+func AsUndo(o *Object) (*Undo, bool) {
+	if o == nil || !o.IsType("Undo") {
+		return nil, false
+	}
+	return &Undo{&Activity{o}}, true
+}
+
+// Update is a typed view of an ActivityStreams Update.
+type Update struct {
+	*Activity
+}
+
+// AsUpdate returns a view of the object if its type is Update, or a
+// type that extends it.
+func AsUpdate(o *Object) (*Update, bool) {
+	if o == nil || !o.IsType("Update") {
+		return nil, false
+	}
+	return &Update{&Activity{o}}, true
+}
+
+// View is a typed view of an ActivityStreams View.
+type View struct {
+	*Activity
+}
+
+// AsView returns a view of the object if its type is View, or a
+// type that extends it.
+func AsView(o *Object) (*View, bool) {
+	if o == nil || !o.IsType("View") {
+		return nil, false
+	}
+	return &View{&Activity{o}}, true
+}
+
+// Application is a typed view of an ActivityStreams Application.
+type Application struct {
+	*Actor
+}
+
+// AsApplication returns a view of the object if its type is Application, or a
+// type that extends it.
+func AsApplication(o *Object) (*Application, bool) {
+	if o == nil || !o.IsType("Application") {
+		return nil, false
+	}
+	return &Application{&Actor{o}}, true
+}
+
+// Group is a typed view of an ActivityStreams Group.
+type Group struct {
+	*Actor
+}
+
+// AsGroup returns a view of the object if its type is Group, or a
+// type that extends it.
+func AsGroup(o *Object) (*Group, bool) {
+	if o == nil || !o.IsType("Group") {
+		return nil, false
+	}
+	return &Group{&Actor{o}}, true
+}
+
+// Organization is a typed view of an ActivityStreams Organization.
+type Organization struct {
+	*Actor
+}
+
+// AsOrganization returns a view of the object if its type is Organization, or a
+// type that extends it.
+func AsOrganization(o *Object) (*Organization, bool) {
+	if o == nil || !o.IsType("Organization") {
+		return nil, false
+	}
+	return &Organization{&Actor{o}}, true
+}
+
+// Person is a typed view of an ActivityStreams Person.
+type Person struct {
+	*Actor
+}
+
+// AsPerson returns a view of the object if its type is Person, or a
+// type that extends it.
+func AsPerson(o *Object) (*Person, bool) {
+	if o == nil || !o.IsType("Person") {
+		return nil, false
+	}
+	return &Person{&Actor{o}}, true
+}
+
+// Service is a typed view of an ActivityStreams Service.
+type Service struct {
+	*Actor
+}
+
+// AsService returns a view of the object if its type is Service, or a
+// type that extends it.
+func AsService(o *Object) (*Service, bool) {
+	if o == nil || !o.IsType("Service") {
+		return nil, false
+	}
+	return &Service{&Actor{o}}, true
+}
+
+// Article is a typed view of an ActivityStreams Article.
+type Article struct {
+	*Object
+}
+
+// AsArticle returns a view of the object if its type is Article, or a
+// type that extends it.
+func AsArticle(o *Object) (*Article, bool) {
+	if o == nil || !o.IsType("Article") {
+		return nil, false
+	}
+	return &Article{o}, true
+}
+
+// Audio is a typed view of an ActivityStreams Audio.
+type Audio struct {
+	*Document
+}
+
+// AsAudio returns a view of the object if its type is Audio, or a
+// type that extends it.
+func AsAudio(o *Object) (*Audio, bool) {
+	if o == nil || !o.IsType("Audio") {
+		return nil, false
+	}
+	return &Audio{&Document{o}}, true
+}
+
+// Document is a typed view of an ActivityStreams Document.
+type Document struct {
+	*Object
+}
+
+// AsDocument returns a view of the object if its type is Document, or a
+// type that extends it.
+func AsDocument(o *Object) (*Document, bool) {
+	if o == nil || !o.IsType("Document") {
+		return nil, false
+	}
+	return &Document{o}, true
+}
+
+// Blurhash returns the blurhash property.
+func (v *Document) Blurhash() string {
+	return v.Object.Str("blurhash")
+}
+
+// Event is a typed view of an ActivityStreams Event.
+type Event struct {
+	*Object
+}
+
+// AsEvent returns a view of the object if its type is Event, or a
+// type that extends it.
+func AsEvent(o *Object) (*Event, bool) {
+	if o == nil || !o.IsType("Event") {
+		return nil, false
+	}
+	return &Event{o}, true
+}
+
+// Image is a typed view of an ActivityStreams Image.
+type Image struct {
+	*Document
+}
+
+// AsImage returns a view of the object if its type is Image, or a
+// type that extends it.
+func AsImage(o *Object) (*Image, bool) {
+	if o == nil || !o.IsType("Image") {
+		return nil, false
+	}
+	return &Image{&Document{o}}, true
+}
+
+// Note is a typed view of an ActivityStreams Note.
+type Note struct {
+	*Object
+}
+
+// AsNote returns a view of the object if its type is Note, or a
+// type that extends it.
+func AsNote(o *Object) (*Note, bool) {
+	if o == nil || !o.IsType("Note") {
+		return nil, false
+	}
+	return &Note{o}, true
+}
+
+// Page is a typed view of an ActivityStreams Page.
+type Page struct {
+	*Document
+}
+
+// AsPage returns a view of the object if its type is Page, or a
+// type that extends it.
+func AsPage(o *Object) (*Page, bool) {
+	if o == nil || !o.IsType("Page") {
+		return nil, false
+	}
+	return &Page{&Document{o}}, true
+}
+
+// Place is a typed view of an ActivityStreams Place.
+type Place struct {
+	*Object
+}
+
+// AsPlace returns a view of the object if its type is Place, or a
+// type that extends it.
+func AsPlace(o *Object) (*Place, bool) {
+	if o == nil || !o.IsType("Place") {
+		return nil, false
+	}
+	return &Place{o}, true
+}
+
+// Accuracy returns the accuracy property.
+func (v *Place) Accuracy() float64 {
+	return v.Object.Float("accuracy")
+}
+
+// Altitude returns the altitude property.
+func (v *Place) Altitude() float64 {
+	return v.Object.Float("altitude")
+}
+
+// Latitude returns the latitude property.
+func (v *Place) Latitude() float64 {
+	return v.Object.Float("latitude")
+}
+
+// Longitude returns the longitude property.
+func (v *Place) Longitude() float64 {
+	return v.Object.Float("longitude")
+}
+
+// Radius returns the radius property.
+func (v *Place) Radius() float64 {
+	return v.Object.Float("radius")
+}
+
+// Units returns the units property.
+func (v *Place) Units() string {
+	return v.Object.Str("units")
+}
+
+// Profile is a typed view of an ActivityStreams Profile.
+type Profile struct {
+	*Object
+}
+
+// AsProfile returns a view of the object if its type is Profile, or a
+// type that extends it.
+func AsProfile(o *Object) (*Profile, bool) {
+	if o == nil || !o.IsType("Profile") {
+		return nil, false
+	}
+	return &Profile{o}, true
+}
+
+// Describes returns the describes property.
+func (v *Profile) Describes() *Object {
+	return v.Object.Object("describes")
+}
+
+// Relationship is a typed view of an ActivityStreams Relationship.
+type Relationship struct {
+	*Object
+}
+
+// AsRelationship returns a view of the object if its type is Relationship, or a
+// type that extends it.
+func AsRelationship(o *Object) (*Relationship, bool) {
+	if o == nil || !o.IsType("Relationship") {
+		return nil, false
+	}
+	return &Relationship{o}, true
+}
+
+// Objects returns the object property.
+func (v *Relationship) Objects() []*Object {
+	return v.Object.List("object")
+}
+
+// Relationships returns the relationship property.
+func (v *Relationship) Relationships() []*Object {
+	return v.Object.List("relationship")
+}
+
+// Subject returns the subject property.
+func (v *Relationship) Subject() *Object {
+	return v.Object.Object("subject")
+}
+
+// Tombstone is a typed view of an ActivityStreams Tombstone.
+type Tombstone struct {
+	*Object
+}
+
+// AsTombstone returns a view of the object if its type is Tombstone, or a
+// type that extends it.
+func AsTombstone(o *Object) (*Tombstone, bool) {
+	if o == nil || !o.IsType("Tombstone") {
+		return nil, false
+	}
+	return &Tombstone{o}, true
+}
+
+// Deleted returns the deleted property.
+func (v *Tombstone) Deleted() time.Time {
+	return v.Object.Time("deleted")
+}
+
+// FormerType returns the formerType property.
+func (v *Tombstone) FormerType() []string {
+	return v.Object.IDs("formerType")
+}
+
+// Video is a typed view of an ActivityStreams Video.
+type Video struct {
+	*Document
+}
+
+// AsVideo returns a view of the object if its type is Video, or a
+// type that extends it.
+func AsVideo(o *Object) (*Video, bool) {
+	if o == nil || !o.IsType("Video") {
+		return nil, false
+	}
+	return &Video{&Document{o}}, true
+}
+
+// Mention is a typed view of an ActivityStreams Mention.
+type Mention struct {
+	*Link
+}
+
+// AsMention returns a view of the object if its type is Mention, or a
+// type that extends it.
+func AsMention(o *Object) (*Mention, bool) {
+	if o == nil || !o.IsType("Mention") {
+		return nil, false
+	}
+	return &Mention{&Link{o}}, true
+}
+
+// Emoji is a typed view of the Emoji extension type.
+type Emoji struct {
+	*Object
+}
+
+// AsEmoji returns a view of the object if its type is Emoji, or a
+// type that extends it.
+func AsEmoji(o *Object) (*Emoji, bool) {
+	if o == nil || !o.IsType("Emoji") {
+		return nil, false
+	}
+	return &Emoji{o}, true
+}
+
+// Hashtag is a typed view of the Hashtag extension type.
+type Hashtag struct {
+	*Link
+}
+
+// AsHashtag returns a view of the object if its type is Hashtag, or a
+// type that extends it.
+func AsHashtag(o *Object) (*Hashtag, bool) {
+	if o == nil || !o.IsType("Hashtag") {
+		return nil, false
+	}
+	return &Hashtag{&Link{o}}, true
+}
+
+// IdentityProof is a typed view of the IdentityProof extension type.
+type IdentityProof struct {
+	*Object
+}
+
+// AsIdentityProof returns a view of the object if its type is IdentityProof, or a
+// type that extends it.
+func AsIdentityProof(o *Object) (*IdentityProof, bool) {
+	if o == nil || !o.IsType("IdentityProof") {
+		return nil, false
+	}
+	return &IdentityProof{o}, true
+}
+
+// PropertyValue is a typed view of the PropertyValue extension type.
+type PropertyValue struct {
+	*Object
+}
+
+// AsPropertyValue returns a view of the object if its type is PropertyValue, or a
+// type that extends it.
+func AsPropertyValue(o *Object) (*PropertyValue, bool) {
+	if o == nil || !o.IsType("PropertyValue") {
+		return nil, false
+	}
+	return &PropertyValue{o}, true
+}
+
+// Value returns the value property.
+func (v *PropertyValue) Value() string {
+	return v.Object.Str("value")
+}
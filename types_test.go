@@ -0,0 +1,123 @@
+package apub_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+)
+
+func TestTypedViews(t *testing.T) {
+	t.Run("person", func(t *testing.T) {
+		obj := Parse(t, `{
+			"id": "https://mastodon.gamedev.place/users/bob",
+			"type": "Person",
+			"inbox": "https://mastodon.gamedev.place/users/bob/inbox",
+			"followers": "https://mastodon.gamedev.place/users/bob/followers",
+			"preferredUsername": "bob",
+			"manuallyApprovesFollowers": true,
+			"published": "2019-04-14T17:19:09Z",
+			"endpoints": {
+				"sharedInbox": "https://mastodon.gamedev.place/inbox"
+			}
+		}`)
+
+		person, ok := apub.AsPerson(obj)
+		require.True(t, ok)
+		assert.Equal(t, "bob", person.PreferredUsername())
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob/inbox", person.Inbox())
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob/followers", person.Followers())
+		assert.Equal(t, "https://mastodon.gamedev.place/inbox", person.Endpoints().Str("sharedInbox"))
+		assert.True(t, person.ManuallyApprovesFollowers())
+		assert.Equal(t, time.Date(2019, 4, 14, 17, 19, 9, 0, time.UTC), person.Published())
+		assert.Equal(t, "https://mastodon.gamedev.place/users/bob", person.ID())
+		assert.Equal(t, obj, person.Object)
+
+		actor, ok := apub.AsActor(obj)
+		require.True(t, ok)
+		assert.Equal(t, "bob", actor.PreferredUsername())
+
+		_, ok = apub.AsNote(obj)
+		assert.False(t, ok)
+		_, ok = apub.AsActivity(obj)
+		assert.False(t, ok)
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("activity", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Question",
+			"actor": "https://example.com/~erik",
+			"oneOf": [
+				{"type": "Note", "name": "yes"},
+				{"type": "Note", "name": "no"}
+			]
+		}`)
+
+		question, ok := apub.AsQuestion(obj)
+		require.True(t, ok)
+		assert.Equal(t, []string{"https://example.com/~erik"}, question.Actor())
+		options := question.OneOf()
+		if assert.Equal(t, 2, len(options)) {
+			assert.Equal(t, "yes", options[0].Name(""))
+		}
+
+		act, ok := apub.AsActivity(obj)
+		require.True(t, ok)
+		assert.Equal(t, []string{"https://example.com/~erik"}, act.Actor())
+
+		_, ok = apub.AsIntransitiveActivity(obj)
+		assert.True(t, ok)
+		_, ok = apub.AsCreate(obj)
+		assert.False(t, ok)
+	})
+
+	t.Run("ordered collection page", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "OrderedCollectionPage",
+			"partOf": "https://example.com/outbox",
+			"next": "https://example.com/outbox?page=2",
+			"totalItems": 3,
+			"startIndex": 1,
+			"orderedItems": ["https://example.com/1"]
+		}`)
+
+		page, ok := apub.AsOrderedCollectionPage(obj)
+		require.True(t, ok)
+		assert.Equal(t, "https://example.com/outbox", page.PartOf().ID())
+		assert.Equal(t, "https://example.com/outbox?page=2", page.Next().ID())
+		assert.Equal(t, 3, page.TotalItems())
+		assert.Equal(t, 1, page.StartIndex())
+		assert.Equal(t, 1, len(page.OrderedItems()))
+
+		_, ok = apub.AsCollectionPage(obj)
+		assert.True(t, ok)
+		_, ok = apub.AsCollection(obj)
+		assert.True(t, ok)
+	})
+
+	t.Run("link", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Mention",
+			"href": "https://example.com/~erik",
+			"name": "@erik"
+		}`)
+
+		mention, ok := apub.AsMention(obj)
+		require.True(t, ok)
+		assert.Equal(t, "https://example.com/~erik", mention.Href())
+		assert.Equal(t, "@erik", mention.Name(""))
+
+		_, ok = apub.AsLink(obj)
+		assert.True(t, ok)
+		assert.False(t, obj.IsType("Object"))
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, ok := apub.AsNote(nil)
+		assert.False(t, ok)
+	})
+}
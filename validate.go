@@ -0,0 +1,220 @@
+package apub
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity ranks the findings in a ValidationReport.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Finding is a single problem found by Validate. Path is a JSON pointer to
+// the offending node or value, such as "/object/tag/0/href". Err is one of the
+// sentinel errors, such as ErrMissingActor or ErrInvalidIRI.
+type Finding struct {
+	Path     string
+	Severity Severity
+	Message  string
+	Err      error
+}
+
+func (f *Finding) Error() string {
+	return fmt.Sprintf("%s at %q: %s", f.Severity, f.Path, f.Message)
+}
+
+func (f *Finding) Unwrap() error {
+	return f.Err
+}
+
+type ValidationReport struct {
+	Findings []*Finding
+}
+
+// Valid returns true if the report has no error findings.
+func (r *ValidationReport) Valid() bool {
+	return r.Err() == nil
+}
+
+// Err returns the first error finding, or nil.
+func (r *ValidationReport) Err() error {
+	for _, f := range r.Findings {
+		if f.Severity >= SeverityError {
+			return f
+		}
+	}
+	return nil
+}
+
+// Filter returns the findings with at least the given severity.
+func (r *ValidationReport) Filter(min Severity) []*Finding {
+	var found []*Finding
+	for _, f := range r.Findings {
+		if f.Severity >= min {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+func (r *ValidationReport) add(path string, sev Severity, err error, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, &Finding{
+		Path:     path,
+		Severity: sev,
+		Message:  fmt.Sprintf(format, args...),
+		Err:      err,
+	})
+}
+
+// Validate walks the whole object, including embedded objects, and checks it
+// against the ActivityStreams vocabulary. Unlike Errors, it does not depend on
+// which accessors have been called.
+func Validate(o *Object) *ValidationReport {
+	r := &ValidationReport{}
+	if o != nil {
+		r.validateNode("", o.data, true)
+	}
+	return r
+}
+
+func (r *ValidationReport) validateNode(path string, data map[string]interface{}, root bool) {
+	obj := New(data)
+	ty := obj.Type()
+
+	switch types := data["type"].(type) {
+	case nil:
+		if root {
+			r.add(path, SeverityError, ErrMissingType, "object has no type")
+		}
+	case string:
+		r.validateType(path+"/type", types)
+	case []interface{}:
+		for i, t := range types {
+			if s, ok := t.(string); ok {
+				r.validateType(path+"/type/"+strconv.Itoa(i), s)
+			}
+		}
+	}
+
+	if len(ty) > 0 && obj.IsType("Activity") {
+		if _, ok := data["id"]; !ok {
+			r.add(path, SeverityError, ErrMissingID, "%s has no id", ty)
+		}
+		if _, ok := data["actor"]; !ok {
+			r.add(path, SeverityError, ErrMissingActor, "%s has no actor", ty)
+		}
+	}
+
+	for _, key := range sortedKeys(data) {
+		if key == "type" || strings.HasPrefix(key, "@") {
+			continue
+		}
+
+		keyPath := path + "/" + pointerToken(key)
+		prop, ok := obj.property(key)
+		if !ok {
+			prop = vocabProperty{}
+		}
+
+		list, isList := data[key].([]interface{})
+		if !isList {
+			r.validateValue(keyPath, key, prop, data[key])
+			continue
+		}
+
+		if prop.functional {
+			switch {
+			case len(list) > 1:
+				r.add(keyPath, SeverityError, ErrFunctionalList,
+					"functional property %q has %d values", key, len(list))
+			case len(list) == 1:
+				r.add(keyPath, SeverityWarning, ErrFunctionalList,
+					"functional property %q is an array", key)
+			}
+		}
+
+		for i, ival := range list {
+			r.validateValue(keyPath+"/"+strconv.Itoa(i), key, prop, ival)
+		}
+	}
+}
+
+func (r *ValidationReport) validateType(path, ty string) {
+	if _, ok := vocabTypes[ty]; !ok {
+		r.add(path, SeverityInfo, ErrUnknownType, "unknown type %q", ty)
+	}
+}
+
+func (r *ValidationReport) validateValue(path, key string, prop vocabProperty, ival interface{}) {
+	// Mastodon and Pixelfed send null for unset properties, like inReplyTo.
+	if ival == nil {
+		return
+	}
+
+	switch val := ival.(type) {
+	case map[string]interface{}:
+		if prop.dateTime {
+			r.add(path, SeverityError, ErrInvalidTime, "%q is not an xsd:dateTime", key)
+			return
+		}
+		r.validateNode(path, val, false)
+	case string:
+		if prop.dateTime {
+			if _, err := time.Parse(time.RFC3339, val); err != nil {
+				r.add(path, SeverityError, ErrInvalidTime,
+					"%q is not an xsd:dateTime: %q", key, val)
+			}
+		}
+		if prop.iri && !isIRI(val) {
+			r.add(path, SeverityError, ErrInvalidIRI,
+				"%q is not an absolute IRI: %q", key, val)
+		}
+	default:
+		if prop.dateTime {
+			r.add(path, SeverityError, ErrInvalidTime,
+				"%q is not an xsd:dateTime: (%T) %v", key, ival, ival)
+		}
+		if prop.iri {
+			r.add(path, SeverityError, ErrInvalidIRI,
+				"%q is not an absolute IRI: (%T) %v", key, ival, ival)
+		}
+	}
+}
+
+// isIRI returns true for absolute IRIs, blank node identifiers, and the
+// compact "Public" term from the ActivityStreams context.
+func isIRI(s string) bool {
+	if s == "Public" || strings.HasPrefix(s, "_:") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
+
+// pointerToken escapes a key for use in a JSON pointer, as described in
+// RFC 6901.
+func pointerToken(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	return strings.ReplaceAll(key, "/", "~1")
+}
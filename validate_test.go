@@ -0,0 +1,150 @@
+package apub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		obj := Parse(t, `{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id": "https://example.com/create/1",
+			"type": "Create",
+			"actor": "https://example.com/~erik",
+			"published": "2019-04-14T17:19:09Z",
+			"to": ["https://www.w3.org/ns/activitystreams#Public"],
+			"cc": ["Public"],
+			"object": {
+				"id": "https://example.com/note/1",
+				"type": "Note",
+				"contentMap": {"en": "hi"},
+				"tag": [{"type": "Mention", "href": "https://example.com/~bob"}]
+			}
+		}`)
+
+		report := apub.Validate(obj)
+		assert.True(t, report.Valid())
+		assert.Nil(t, report.Err())
+		assert.Equal(t, 0, len(report.Findings))
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		report := apub.Validate(Parse(t, `{"id": "https://example.com/1"}`))
+		assert.False(t, report.Valid())
+		require.Equal(t, 1, len(report.Findings))
+		f := report.Findings[0]
+		assert.Equal(t, "", f.Path)
+		assert.Equal(t, apub.SeverityError, f.Severity)
+		assert.True(t, xerrors.Is(report.Err(), apub.ErrMissingType))
+	})
+
+	t.Run("activity", func(t *testing.T) {
+		report := apub.Validate(Parse(t, `{
+			"id": "https://example.com/announce/1",
+			"type": "Announce",
+			"actor": "https://example.com/~erik",
+			"object": {"type": "Arrive", "location": "https://example.com/place"}
+		}`))
+
+		assert.False(t, report.Valid())
+		if assert.Equal(t, 2, len(report.Findings)) {
+			assert.Equal(t, "/object", report.Findings[0].Path)
+			assert.Equal(t, apub.ErrMissingID, report.Findings[0].Err)
+			assert.Equal(t, "/object", report.Findings[1].Path)
+			assert.Equal(t, apub.ErrMissingActor, report.Findings[1].Err)
+		}
+	})
+
+	t.Run("functional properties", func(t *testing.T) {
+		report := apub.Validate(Parse(t, `{
+			"type": "Person",
+			"inbox": ["https://example.com/inbox"],
+			"outbox": ["https://example.com/outbox", "https://example.com/outbox2"],
+			"name": ["erik", "bob"]
+		}`))
+
+		if assert.Equal(t, 2, len(report.Findings)) {
+			assert.Equal(t, "/inbox", report.Findings[0].Path)
+			assert.Equal(t, apub.SeverityWarning, report.Findings[0].Severity)
+			assert.Equal(t, "/outbox", report.Findings[1].Path)
+			assert.Equal(t, apub.SeverityError, report.Findings[1].Severity)
+			assert.True(t, xerrors.Is(report.Findings[1], apub.ErrFunctionalList))
+		}
+		assert.Equal(t, 1, len(report.Filter(apub.SeverityError)))
+		assert.Equal(t, 2, len(report.Filter(apub.SeverityWarning)))
+	})
+
+	t.Run("values", func(t *testing.T) {
+		report := apub.Validate(Parse(t, `{
+			"id": "https://example.com/create/1",
+			"type": "Create",
+			"actor": "erik",
+			"published": "yesterday",
+			"object": {
+				"type": "Note",
+				"updated": 1555262349,
+				"inReplyTo": "https://example.com/note/1",
+				"tag": [
+					{"type": "Hashtag", "href": "/tags/go"},
+					{"type": "Mention", "href": "_:b0"}
+				]
+			}
+		}`))
+
+		expected := []struct {
+			path string
+			err  error
+		}{
+			{"/actor", apub.ErrInvalidIRI},
+			{"/object/tag/0/href", apub.ErrInvalidIRI},
+			{"/object/updated", apub.ErrInvalidTime},
+			{"/published", apub.ErrInvalidTime},
+		}
+		if assert.Equal(t, len(expected), len(report.Findings)) {
+			for i, exp := range expected {
+				assert.Equal(t, exp.path, report.Findings[i].Path, exp.path)
+				assert.Equal(t, exp.err, report.Findings[i].Err, exp.path)
+			}
+		}
+	})
+
+	t.Run("unknown types and escaped keys", func(t *testing.T) {
+		report := apub.Validate(Parse(t, `{
+			"type": ["Note", "litepub:ChatMessage"],
+			"http://example.com/ns/a~b": {"type": "Emoji", "icon": "icon.png"}
+		}`))
+
+		if assert.Equal(t, 2, len(report.Findings)) {
+			assert.Equal(t, "/type/1", report.Findings[0].Path)
+			assert.Equal(t, apub.SeverityInfo, report.Findings[0].Severity)
+			assert.Equal(t, apub.ErrUnknownType, report.Findings[0].Err)
+			assert.Equal(t, "/http:~1~1example.com~1ns~1a~0b/icon", report.Findings[1].Path)
+			assert.Equal(t, apub.ErrInvalidIRI, report.Findings[1].Err)
+		}
+		assert.False(t, report.Valid())
+	})
+
+	t.Run("fixtures", func(t *testing.T) {
+		fixtures := map[string]string{
+			"mastodon person":   mastodonPersonJSON,
+			"mastodon featured": mastodonFeaturedJSON,
+			"mastodon note":     mastodonNoteJSON,
+			"pixelfed person":   pixelfedPersonJSON,
+			"pixelfed note":     pixelfedNoteJSON,
+		}
+		for name, fixture := range fixtures {
+			report := apub.Validate(Parse(t, fixture))
+			assert.True(t, report.Valid(), "%s: %v", name, report.Err())
+			assert.Nil(t, report.Filter(apub.SeverityWarning), name)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.True(t, apub.Validate(nil).Valid())
+	})
+}
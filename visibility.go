@@ -0,0 +1,132 @@
+package apub
+
+import "fmt"
+
+// VisibilityLevel classifies who an object is addressed to, with the same
+// levels as Mastodon.
+type VisibilityLevel int
+
+const (
+	// VisibilityPublic objects are addressed to the Public collection.
+	VisibilityPublic VisibilityLevel = iota
+
+	// VisibilityUnlisted objects cc the Public collection, so they are
+	// visible to everyone but left out of public timelines.
+	VisibilityUnlisted
+
+	// VisibilityPrivate objects are addressed to the author's followers.
+	VisibilityPrivate
+
+	// VisibilityLimited objects are addressed to actors or collections that
+	// are not all mentioned, such as a circle or group.
+	VisibilityLimited
+
+	// VisibilityDirect objects are addressed only to the mentioned actors.
+	VisibilityDirect
+
+	// VisibilityUnknown objects have no to, cc, bto, bcc, or audience.
+	VisibilityUnknown
+)
+
+func (v VisibilityLevel) String() string {
+	switch v {
+	case VisibilityPublic:
+		return "public"
+	case VisibilityUnlisted:
+		return "unlisted"
+	case VisibilityPrivate:
+		return "private"
+	case VisibilityLimited:
+		return "limited"
+	case VisibilityDirect:
+		return "direct"
+	case VisibilityUnknown:
+		return "unknown"
+	default:
+		return fmt.Sprintf("VisibilityLevel(%d)", int(v))
+	}
+}
+
+// Visibility classifies the object by its to, cc, and audience. Activities
+// without any addressing are classified by their object instead.
+//
+// The author's followers collection is read from an embedded actor or
+// attributedTo. It is assumed to be the author's id with "/followers", as
+// Mastodon and Pixelfed use, only if the author is an IRI.
+func Visibility(o *Object) VisibilityLevel {
+	target := o
+	if !isAddressed(o) {
+		if obj, err := o.FetchObject("object"); err == nil && obj != nil {
+			target = obj
+		}
+	}
+
+	to := append(target.To(), target.Audience()...)
+	cc := target.CC()
+	for _, id := range to {
+		if IsPublic(id) {
+			return VisibilityPublic
+		}
+	}
+	for _, id := range cc {
+		if IsPublic(id) {
+			return VisibilityUnlisted
+		}
+	}
+
+	recipients := append(append(to, cc...), append(target.BTo(), target.BCC()...)...)
+	if len(recipients) == 0 {
+		return VisibilityUnknown
+	}
+
+	followers := make(map[string]bool)
+	authorFollowers(followers, o)
+	authorFollowers(followers, target)
+	for _, id := range recipients {
+		if followers[id] {
+			return VisibilityPrivate
+		}
+	}
+
+	mentioned := make(map[string]bool)
+	for _, tag := range target.Tags() {
+		if tag.Type() == "Mention" {
+			mentioned[tag.DefaultValue()] = true
+		}
+	}
+	for _, id := range recipients {
+		if !mentioned[id] {
+			return VisibilityLimited
+		}
+	}
+	return VisibilityDirect
+}
+
+func isAddressed(o *Object) bool {
+	for k := range activityAudienceAttrs {
+		if _, _, ok := o.lookup(k); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authorFollowers adds the followers collections of the object's actor and
+// attributedTo.
+func authorFollowers(followers map[string]bool, o *Object) {
+	for _, key := range []string{"actor", "attributedTo"} {
+		authors, err := o.FetchList(key)
+		if err != nil {
+			continue
+		}
+		for _, author := range authors {
+			if !isReference(author) {
+				if f := author.Str("followers"); len(f) > 0 {
+					followers[f] = true
+				}
+			} else if id := author.ID(); len(id) > 0 {
+				followers[id+"/followers"] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package apub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/technoweenie/apub"
+)
+
+func TestVisibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected apub.VisibilityLevel
+	}{
+		{
+			name: "public",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"to": ["https://www.w3.org/ns/activitystreams#Public"],
+				"cc": ["https://example.com/~erik/followers"]
+			}`,
+			expected: apub.VisibilityPublic,
+		},
+		{
+			name: "public alias",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"to": "as:Public"
+			}`,
+			expected: apub.VisibilityPublic,
+		},
+		{
+			name: "public audience",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"audience": "as#Public"
+			}`,
+			expected: apub.VisibilityPublic,
+		},
+		{
+			name: "unlisted",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"to": ["https://example.com/~erik/followers"],
+				"cc": ["https://www.w3.org/ns/activitystreams#Public"]
+			}`,
+			expected: apub.VisibilityUnlisted,
+		},
+		{
+			name: "private",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"to": ["https://example.com/~erik/followers"],
+				"cc": ["https://b.example/carol"],
+				"tag": [{"type": "Mention", "href": "https://b.example/carol"}]
+			}`,
+			expected: apub.VisibilityPrivate,
+		},
+		{
+			name: "private with embedded actor",
+			input: `{
+				"type": "Create",
+				"actor": {
+					"id": "https://example.com/users/erik",
+					"type": "Person",
+					"followers": "https://example.com/followers/erik"
+				},
+				"object": {
+					"type": "Note",
+					"to": "https://example.com/followers/erik"
+				}
+			}`,
+			expected: apub.VisibilityPrivate,
+		},
+		{
+			name: "direct",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"to": ["https://b.example/carol"],
+				"tag": [
+					{"type": "Mention", "href": "https://b.example/carol", "name": "@carol@b.example"},
+					{"type": "Hashtag", "href": "https://example.com/tags/test", "name": "#test"}
+				]
+			}`,
+			expected: apub.VisibilityDirect,
+		},
+		{
+			name: "activity addressing",
+			input: `{
+				"type": "Create",
+				"actor": "https://example.com/~erik",
+				"to": ["https://b.example/carol"],
+				"object": {
+					"type": "Note",
+					"to": ["https://www.w3.org/ns/activitystreams#Public"],
+					"tag": {"type": "Mention", "href": "https://b.example/carol"}
+				}
+			}`,
+			expected: apub.VisibilityLimited,
+		},
+		{
+			name: "limited",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"to": ["https://example.com/~erik/circles/1"]
+			}`,
+			expected: apub.VisibilityLimited,
+		},
+		{
+			name: "embedded actor without followers",
+			input: `{
+				"type": "Note",
+				"attributedTo": {"id": "https://example.com/~erik", "type": "Person"},
+				"to": ["https://example.com/~erik/followers"]
+			}`,
+			expected: apub.VisibilityLimited,
+		},
+		{
+			name: "unaddressed",
+			input: `{
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"tag": {"type": "Mention", "href": "https://b.example/carol"}
+			}`,
+			expected: apub.VisibilityUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := apub.Visibility(Parse(t, test.input))
+			assert.Equal(t, test.expected, v, v.String())
+		})
+	}
+
+	assert.Equal(t, "unlisted", apub.VisibilityUnlisted.String())
+	assert.Equal(t, "VisibilityLevel(9)", apub.VisibilityLevel(9).String())
+}
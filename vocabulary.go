@@ -0,0 +1,107 @@
+package apub
+
+//go:generate go run gen_vocabulary.go
+
+// vocabType describes an ActivityStreams type and the types it extends.
+type vocabType struct {
+	extends []string
+
+	// defaultKey is the property returned by DefaultValue, and set when
+	// building an object of this type from a bare IRI.
+	defaultKey string
+}
+
+// vocabProperty describes an ActivityStreams property.
+type vocabProperty struct {
+	domain []string
+
+	// iriType is the type of the object built from a bare IRI value. Bare IRIs
+	// of properties without one become objects with only an id.
+	iriType string
+
+	// functional properties have at most one value.
+	functional bool
+
+	// iri properties have IRI references as their string values.
+	iri bool
+
+	// dateTime properties have xsd:dateTime values.
+	dateTime bool
+}
+
+// IsType returns true if the object's type is ty, or a type that extends it.
+func (o *Object) IsType(ty string) bool {
+	return extendsType(o.Type(), ty)
+}
+
+// extendsType returns true if ty is parent, or a subtype of parent. Unknown
+// types are treated as extensions of Object.
+func extendsType(ty, parent string) bool {
+	if ty == parent {
+		return true
+	}
+
+	vt, ok := vocabTypes[ty]
+	if !ok {
+		return parent == "Object"
+	}
+
+	for _, ext := range vt.extends {
+		if extendsType(ext, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultKey returns the default property of the given type, inherited from
+// its nearest ancestor that has one.
+func defaultKey(ty string) string {
+	vt, ok := vocabTypes[ty]
+	if !ok {
+		return ""
+	}
+	if len(vt.defaultKey) > 0 {
+		return vt.defaultKey
+	}
+	for _, ext := range vt.extends {
+		if key := defaultKey(ext); len(key) > 0 {
+			return key
+		}
+	}
+	return ""
+}
+
+// property returns the vocabulary definition of the given key, if the
+// object's type is in its domain.
+func (o *Object) property(key string) (vocabProperty, bool) {
+	prop, ok := vocabProperties[key]
+	if !ok {
+		return prop, false
+	}
+
+	ty := o.firstType()
+	for _, domain := range prop.domain {
+		if extendsType(ty, domain) {
+			return prop, true
+		}
+	}
+	return prop, false
+}
+
+// firstType returns the object's type, or the first of a list of types,
+// without building typed values from the vocabulary. Type() can't be used,
+// since its values are built with property() for each type in a list.
+func (o *Object) firstType() string {
+	_, ival, _ := o.lookup("type")
+	switch val := ival.(type) {
+	case string:
+		return val
+	case []interface{}:
+		if len(val) > 0 {
+			s, _ := val[0].(string)
+			return s
+		}
+	}
+	return ""
+}
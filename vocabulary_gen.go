@@ -0,0 +1,152 @@
+// Code generated by gen_vocabulary.go; DO NOT EDIT.
+
+package apub
+
+var vocabTypes = map[string]vocabType{
+	"Accept":                {extends: []string{"Activity"}},
+	"Activity":              {extends: []string{"Object"}},
+	"Actor":                 {extends: []string{"Object"}},
+	"Add":                   {extends: []string{"Activity"}},
+	"Announce":              {extends: []string{"Activity"}},
+	"Application":           {extends: []string{"Actor"}},
+	"Arrive":                {extends: []string{"IntransitiveActivity"}},
+	"Article":               {extends: []string{"Object"}},
+	"Audio":                 {extends: []string{"Document"}},
+	"Block":                 {extends: []string{"Ignore"}},
+	"Collection":            {extends: []string{"Object"}},
+	"CollectionPage":        {extends: []string{"Collection"}},
+	"Create":                {extends: []string{"Activity"}},
+	"Delete":                {extends: []string{"Activity"}},
+	"Dislike":               {extends: []string{"Activity"}},
+	"Document":              {extends: []string{"Object"}},
+	"Emoji":                 {extends: []string{"Object"}},
+	"Event":                 {extends: []string{"Object"}},
+	"Flag":                  {extends: []string{"Activity"}},
+	"Follow":                {extends: []string{"Activity"}},
+	"Group":                 {extends: []string{"Actor"}},
+	"Hashtag":               {extends: []string{"Link"}},
+	"IdentityProof":         {extends: []string{"Object"}},
+	"Ignore":                {extends: []string{"Activity"}},
+	"Image":                 {extends: []string{"Document"}, defaultKey: "url"},
+	"IntransitiveActivity":  {extends: []string{"Activity"}},
+	"Invite":                {extends: []string{"Offer"}},
+	"Join":                  {extends: []string{"Activity"}},
+	"Leave":                 {extends: []string{"Activity"}},
+	"Like":                  {extends: []string{"Activity"}},
+	"Link":                  {defaultKey: "href"},
+	"Listen":                {extends: []string{"Activity"}},
+	"Mention":               {extends: []string{"Link"}},
+	"Move":                  {extends: []string{"Activity"}},
+	"Note":                  {extends: []string{"Object"}},
+	"Object":                {},
+	"Offer":                 {extends: []string{"Activity"}},
+	"OrderedCollection":     {extends: []string{"Collection"}},
+	"OrderedCollectionPage": {extends: []string{"OrderedCollection", "CollectionPage"}},
+	"Organization":          {extends: []string{"Actor"}},
+	"Page":                  {extends: []string{"Document"}},
+	"Person":                {extends: []string{"Actor"}},
+	"Place":                 {extends: []string{"Object"}},
+	"Profile":               {extends: []string{"Object"}},
+	"PropertyValue":         {extends: []string{"Object"}},
+	"Question":              {extends: []string{"IntransitiveActivity"}},
+	"Read":                  {extends: []string{"Activity"}},
+	"Reject":                {extends: []string{"Activity"}},
+	"Relationship":          {extends: []string{"Object"}},
+	"Remove":                {extends: []string{"Activity"}},
+	"Service":               {extends: []string{"Actor"}},
+	"TentativeAccept":       {extends: []string{"Accept"}},
+	"TentativeReject":       {extends: []string{"Reject"}},
+	"Tombstone":             {extends: []string{"Object"}},
+	"Travel":                {extends: []string{"IntransitiveActivity"}},
+	"Undo":                  {extends: []string{"Activity"}},
+	"Update":                {extends: []string{"Activity"}},
+	"Video":                 {extends: []string{"Document"}},
+	"View":                  {extends: []string{"Activity"}},
+}
+
+var vocabProperties = map[string]vocabProperty{
+	"accuracy":                  {domain: []string{"Place"}, functional: true},
+	"actor":                     {domain: []string{"Activity"}, iri: true},
+	"alsoKnownAs":               {domain: []string{"Object"}, iri: true},
+	"altitude":                  {domain: []string{"Place"}, functional: true},
+	"anyOf":                     {domain: []string{"Question"}, iri: true},
+	"attachment":                {domain: []string{"Object"}, iri: true},
+	"attributedTo":              {domain: []string{"Object", "Link"}, iri: true},
+	"audience":                  {domain: []string{"Object"}, iri: true},
+	"bcc":                       {domain: []string{"Object"}, iri: true},
+	"blurhash":                  {domain: []string{"Document"}, functional: true},
+	"bto":                       {domain: []string{"Object"}, iri: true},
+	"cc":                        {domain: []string{"Object"}, iri: true},
+	"closed":                    {domain: []string{"Question"}},
+	"content":                   {domain: []string{"Object"}},
+	"context":                   {domain: []string{"Object"}, iri: true},
+	"current":                   {domain: []string{"Collection"}, functional: true, iri: true},
+	"deleted":                   {domain: []string{"Tombstone"}, functional: true, dateTime: true},
+	"describes":                 {domain: []string{"Profile"}, functional: true, iri: true},
+	"discoverable":              {domain: []string{"Actor"}, functional: true},
+	"duration":                  {domain: []string{"Object"}, functional: true},
+	"endTime":                   {domain: []string{"Object"}, functional: true, dateTime: true},
+	"endpoints":                 {domain: []string{"Actor"}, functional: true, iri: true},
+	"featured":                  {domain: []string{"Actor"}, functional: true, iri: true},
+	"featuredTags":              {domain: []string{"Actor"}, functional: true, iri: true},
+	"first":                     {domain: []string{"Collection"}, functional: true, iri: true},
+	"followers":                 {domain: []string{"Actor"}, functional: true, iri: true},
+	"following":                 {domain: []string{"Actor"}, functional: true, iri: true},
+	"formerType":                {domain: []string{"Tombstone"}},
+	"generator":                 {domain: []string{"Object"}, iri: true},
+	"height":                    {domain: []string{"Link"}, functional: true},
+	"href":                      {domain: []string{"Link"}, functional: true, iri: true},
+	"hreflang":                  {domain: []string{"Link"}, functional: true},
+	"icon":                      {domain: []string{"Object"}, iriType: "Image", iri: true},
+	"id":                        {domain: []string{"Object", "Link"}, functional: true, iri: true},
+	"image":                     {domain: []string{"Object"}, iriType: "Image", iri: true},
+	"inReplyTo":                 {domain: []string{"Object"}, iri: true},
+	"inbox":                     {domain: []string{"Actor"}, functional: true, iri: true},
+	"instrument":                {domain: []string{"Activity"}, iri: true},
+	"items":                     {domain: []string{"Collection"}, iri: true},
+	"last":                      {domain: []string{"Collection"}, functional: true, iri: true},
+	"latitude":                  {domain: []string{"Place"}, functional: true},
+	"liked":                     {domain: []string{"Actor"}, functional: true, iri: true},
+	"likes":                     {domain: []string{"Object"}, functional: true, iri: true},
+	"location":                  {domain: []string{"Object"}, iri: true},
+	"longitude":                 {domain: []string{"Place"}, functional: true},
+	"manuallyApprovesFollowers": {domain: []string{"Actor"}, functional: true},
+	"mediaType":                 {domain: []string{"Object", "Link"}, functional: true},
+	"movedTo":                   {domain: []string{"Actor"}, functional: true, iri: true},
+	"name":                      {domain: []string{"Object", "Link"}},
+	"next":                      {domain: []string{"CollectionPage"}, functional: true, iri: true},
+	"object":                    {domain: []string{"Activity", "Relationship"}, iri: true},
+	"oneOf":                     {domain: []string{"Question"}, iri: true},
+	"orderedItems":              {domain: []string{"OrderedCollection"}, iri: true},
+	"origin":                    {domain: []string{"Activity"}, iri: true},
+	"outbox":                    {domain: []string{"Actor"}, functional: true, iri: true},
+	"partOf":                    {domain: []string{"CollectionPage"}, functional: true, iri: true},
+	"preferredUsername":         {domain: []string{"Actor"}, functional: true},
+	"prev":                      {domain: []string{"CollectionPage"}, functional: true, iri: true},
+	"preview":                   {domain: []string{"Object", "Link"}, iriType: "Link", iri: true},
+	"publicKey":                 {domain: []string{"Actor"}, iri: true},
+	"published":                 {domain: []string{"Object"}, functional: true, dateTime: true},
+	"radius":                    {domain: []string{"Place"}, functional: true},
+	"rel":                       {domain: []string{"Link"}},
+	"relationship":              {domain: []string{"Relationship"}, iri: true},
+	"replies":                   {domain: []string{"Object"}, functional: true, iri: true},
+	"result":                    {domain: []string{"Activity"}, iri: true},
+	"sensitive":                 {domain: []string{"Object"}, functional: true},
+	"shares":                    {domain: []string{"Object"}, functional: true, iri: true},
+	"source":                    {domain: []string{"Object"}, functional: true},
+	"startIndex":                {domain: []string{"OrderedCollectionPage"}, functional: true},
+	"startTime":                 {domain: []string{"Object"}, functional: true, dateTime: true},
+	"streams":                   {domain: []string{"Actor"}, iri: true},
+	"subject":                   {domain: []string{"Relationship"}, functional: true, iri: true},
+	"summary":                   {domain: []string{"Object"}},
+	"tag":                       {domain: []string{"Object"}, iri: true},
+	"target":                    {domain: []string{"Activity"}, iri: true},
+	"to":                        {domain: []string{"Object"}, iri: true},
+	"totalItems":                {domain: []string{"Collection"}, functional: true},
+	"type":                      {domain: []string{"Object", "Link"}},
+	"units":                     {domain: []string{"Place"}, functional: true},
+	"updated":                   {domain: []string{"Object"}, functional: true, dateTime: true},
+	"url":                       {domain: []string{"Object"}, iriType: "Link", iri: true},
+	"value":                     {domain: []string{"PropertyValue"}, functional: true},
+	"width":                     {domain: []string{"Link"}, functional: true},
+}
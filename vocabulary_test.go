@@ -0,0 +1,131 @@
+package apub_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestVocabulary(t *testing.T) {
+	t.Run("bare IRIs", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Create",
+			"actor": "https://example.com/~erik",
+			"icon": "https://example.com/icon.png",
+			"url": "https://example.com/create/1",
+			"object": {
+				"type": "Note",
+				"attributedTo": "https://example.com/~erik",
+				"inReplyTo": "https://example.com/note/1",
+				"location": "https://example.com/places/1",
+				"generator": "https://example.com/apps/1",
+				"preview": "https://example.com/note/2/preview",
+				"icon": "https://example.com/note/2/icon.png"
+			}
+		}`)
+
+		// actors and other objects of any type stay untyped until resolved
+		actor := obj.Object("actor")
+		assert.Equal(t, "", actor.Type())
+		assert.Equal(t, "https://example.com/~erik", actor.ID())
+
+		icon := obj.Object("icon")
+		assert.Equal(t, "Image", icon.Type())
+		assert.Equal(t, "https://example.com/icon.png", icon.Str("url"))
+
+		url := obj.Object("url")
+		assert.Equal(t, "Link", url.Type())
+		assert.Equal(t, "https://example.com/create/1", url.Str("href"))
+
+		note := obj.Object("object")
+		for _, key := range []string{"attributedTo", "inReplyTo", "location", "generator"} {
+			ref := note.Object(key)
+			assert.Equal(t, "", ref.Type(), key)
+			assert.Equal(t, note.Str(key), ref.ID(), key)
+		}
+		assert.Equal(t, "Image", note.Object("icon").Type())
+
+		preview := note.Object("preview")
+		assert.Equal(t, "Link", preview.Type())
+		assert.Equal(t, "https://example.com/note/2/preview", preview.Str("href"))
+		assert.Equal(t, "https://example.com/note/2/preview", preview.DefaultValue())
+
+		first := Parse(t, `{"type": "Collection", "first": "https://example.com/page/1"}`).Object("first")
+		assert.Equal(t, "", first.Type())
+		assert.Equal(t, "https://example.com/page/1", first.ID())
+
+		assert.Nil(t, obj.Errors())
+	})
+
+	t.Run("list of types", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": ["Note", "Other"],
+			"icon": "https://example.com/icon.png",
+			"tag": "https://example.com/tags/go"
+		}`)
+
+		assert.Equal(t, "Note", obj.Type())
+		assert.True(t, obj.IsType("Object"))
+		assert.Equal(t, "Image", obj.Object("icon").Type())
+		assert.Equal(t, "https://example.com/tags/go", obj.Object("tag").ID())
+	})
+
+	t.Run("out of domain", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Link",
+			"href": "https://example.com/link",
+			"icon": "https://example.com/icon.png"
+		}`)
+
+		icon := obj.Object("icon")
+		assert.Equal(t, "", icon.Type())
+		assert.Equal(t, "https://example.com/icon.png", icon.ID())
+	})
+
+	t.Run("default values", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Note",
+			"tag": [
+				{"type": "Mention", "id": "https://example.com/m", "href": "https://example.com/~erik"},
+				{"type": "Hashtag", "href": "https://example.com/tags/go", "name": "#go"},
+				{"type": "Person", "id": "https://example.com/~jane"},
+				{"type": "Image", "id": "https://example.com/i", "url": "https://example.com/i.png"},
+				{"type": "Emoji", "id": "https://example.com/e", "name": ":blob:"}
+			]
+		}`)
+
+		tags := obj.Tags()
+		if assert.Equal(t, 5, len(tags)) {
+			assert.Equal(t, "https://example.com/~erik", tags[0].DefaultValue())
+			assert.Equal(t, "https://example.com/tags/go", tags[1].DefaultValue())
+			assert.Equal(t, "https://example.com/~jane", tags[2].DefaultValue())
+			assert.Equal(t, "https://example.com/i.png", tags[3].DefaultValue())
+			assert.Equal(t, "https://example.com/e", tags[4].DefaultValue())
+		}
+	})
+
+	t.Run("functional properties", func(t *testing.T) {
+		obj := Parse(t, `{
+			"type": "Person",
+			"inbox": ["https://example.com/inbox/1", "https://example.com/inbox/2"],
+			"url": ["https://example.com/1", "https://example.com/2"],
+			"first": ["https://example.com/page/1", "https://example.com/page/2"]
+		}`)
+
+		assert.Equal(t, "https://example.com/1", obj.Str("url"))
+		assert.Nil(t, obj.Errors())
+
+		inbox, err := obj.Fetch("inbox")
+		assert.Equal(t, "https://example.com/inbox/1", inbox)
+		assert.True(t, xerrors.Is(err, apub.ErrFunctionalList), err)
+
+		first, err := obj.FetchObject("first")
+		assert.Equal(t, "https://example.com/page/1", first.ID())
+		assert.Nil(t, err)
+
+		assert.Equal(t, 2, len(obj.List("inbox")))
+		assert.Nil(t, obj.Errors())
+	})
+}
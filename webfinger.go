@@ -0,0 +1,218 @@
+package apub
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Acct is an account URI, such as "acct:bob@mastodon.gamedev.place", as
+// described by RFC 7565.
+type Acct struct {
+	User string
+	Host string
+}
+
+// ParseAcct parses an account URI. The "acct:" scheme is optional, and a
+// leading "@" is allowed, so "@bob@mastodon.gamedev.place" is accepted too.
+func ParseAcct(s string) (Acct, error) {
+	orig := s
+	if len(s) > 5 && strings.EqualFold(s[:5], "acct:") {
+		s = s[5:]
+	} else {
+		s = strings.TrimPrefix(s, "@")
+	}
+
+	at := strings.LastIndexByte(s, '@')
+	if at < 1 || at == len(s)-1 {
+		return Acct{}, xerrors.Errorf("ParseAcct: %q: %w", orig, ErrInvalidAcct)
+	}
+
+	user, err := url.PathUnescape(s[:at])
+	if err != nil || strings.ContainsAny(user, "/?#@ ") {
+		return Acct{}, xerrors.Errorf("ParseAcct: %q: %w", orig, ErrInvalidAcct)
+	}
+
+	host := s[at+1:]
+	u, err := url.Parse("https://" + host)
+	if err != nil || u.Host != host || len(u.Hostname()) == 0 {
+		return Acct{}, xerrors.Errorf("ParseAcct: %q: %w", orig, ErrInvalidAcct)
+	}
+
+	return Acct{User: user, Host: strings.ToLower(host)}, nil
+}
+
+func (a Acct) String() string {
+	return "acct:" + url.PathEscape(a.User) + "@" + a.Host
+}
+
+// JRD is a JSON Resource Descriptor returned by WebFinger.
+type JRD struct {
+	Subject    string             `json:"subject"`
+	Aliases    []string           `json:"aliases,omitempty"`
+	Properties map[string]*string `json:"properties,omitempty"`
+	Links      []*JRDLink         `json:"links,omitempty"`
+}
+
+type JRDLink struct {
+	Rel        string             `json:"rel"`
+	Type       string             `json:"type,omitempty"`
+	Href       string             `json:"href,omitempty"`
+	Template   string             `json:"template,omitempty"`
+	Titles     map[string]string  `json:"titles,omitempty"`
+	Properties map[string]*string `json:"properties,omitempty"`
+}
+
+// Link returns the first link with the given rel, and media type if not
+// empty.
+func (j *JRD) Link(rel, mediaType string) *JRDLink {
+	for _, link := range j.Links {
+		if link.Rel == rel && (len(mediaType) == 0 || link.Type == mediaType) {
+			return link
+		}
+	}
+	return nil
+}
+
+// ActorID returns the href of the self link to an ActivityPub actor.
+func (j *JRD) ActorID() (string, error) {
+	for _, link := range j.Links {
+		if link.Rel == "self" && len(link.Href) > 0 && isActivityMediaType(link.Type) {
+			return link.Href, nil
+		}
+	}
+	return "", xerrors.Errorf("ActorID: %q: %w", j.Subject, ErrNoActorLink)
+}
+
+// WebFingerClient looks up accounts with WebFinger, as described by RFC 7033.
+type WebFingerClient struct {
+	// Client sends the requests. http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// MaxBodySize is the largest accepted JRD. DefaultMaxBodySize is used if
+	// zero.
+	MaxBodySize int64
+}
+
+// Lookup returns the JRD of the account from its host.
+func (c *WebFingerClient) Lookup(ctx context.Context, acct Acct) (*JRD, error) {
+	u := &url.URL{
+		Scheme:   "https",
+		Host:     acct.Host,
+		Path:     "/.well-known/webfinger",
+		RawQuery: url.Values{"resource": {acct.String()}}.Encode(),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("Lookup: %s: %v: %w", acct, err, ErrFetchFailed)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/jrd+json, application/json")
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("Lookup: %s: %v: %w", acct, err, ErrFetchFailed)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, xerrors.Errorf("Lookup: %s: %w", acct, ErrAcctNotFound)
+	default:
+		return nil, xerrors.Errorf("Lookup: %s: %s: %w", acct, res.Status, ErrFetchFailed)
+	}
+
+	max := c.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+
+	jrd := &JRD{}
+	if err := json.NewDecoder(io.LimitReader(res.Body, max)).Decode(jrd); err != nil {
+		return nil, xerrors.Errorf("Lookup: %s: %v: %w", acct, err, ErrFetchFailed)
+	}
+	return jrd, nil
+}
+
+// ActorID looks up the id of the account's ActivityPub actor.
+func (c *WebFingerClient) ActorID(ctx context.Context, acct Acct) (string, error) {
+	jrd, err := c.Lookup(ctx, acct)
+	if err != nil {
+		return "", err
+	}
+	return jrd.ActorID()
+}
+
+// WebFingerLookup finds local accounts for a WebFingerHandler. It returns an
+// error wrapping ErrAcctNotFound for unknown accounts.
+type WebFingerLookup interface {
+	LookupAcct(ctx context.Context, acct Acct) (*JRD, error)
+}
+
+// WebFingerLookupFunc adapts a function to a WebFingerLookup.
+type WebFingerLookupFunc func(ctx context.Context, acct Acct) (*JRD, error)
+
+func (f WebFingerLookupFunc) LookupAcct(ctx context.Context, acct Acct) (*JRD, error) {
+	return f(ctx, acct)
+}
+
+// WebFingerHandler serves /.well-known/webfinger for local accounts.
+type WebFingerHandler struct {
+	Lookup WebFingerLookup
+}
+
+func (h *WebFingerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	acct, err := ParseAcct(query.Get("resource"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	jrd, err := h.Lookup.LookupAcct(r.Context(), acct)
+	if xerrors.Is(err, ErrAcctNotFound) || (err == nil && jrd == nil) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if rels := query["rel"]; len(rels) > 0 {
+		filtered := *jrd
+		filtered.Links = nil
+		for _, link := range jrd.Links {
+			for _, rel := range rels {
+				if link.Rel == rel {
+					filtered.Links = append(filtered.Links, link)
+					break
+				}
+			}
+		}
+		jrd = &filtered
+	}
+
+	w.Header().Set("Content-Type", mime.FormatMediaType("application/jrd+json", map[string]string{"charset": "utf-8"}))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(jrd)
+}
@@ -0,0 +1,136 @@
+package apub_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/technoweenie/apub"
+	"golang.org/x/xerrors"
+)
+
+func TestParseAcct(t *testing.T) {
+	for _, input := range []string{
+		"acct:bob@mastodon.gamedev.place",
+		"ACCT:bob@mastodon.gamedev.place",
+		"@bob@mastodon.gamedev.place",
+		"bob@Mastodon.Gamedev.Place",
+	} {
+		acct, err := apub.ParseAcct(input)
+		if assert.Nil(t, err, input) {
+			assert.Equal(t, "bob", acct.User, input)
+			assert.Equal(t, "mastodon.gamedev.place", acct.Host, input)
+			assert.Equal(t, "acct:bob@mastodon.gamedev.place", acct.String())
+		}
+	}
+
+	acct, err := apub.ParseAcct("acct:bob@localhost:3000")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:3000", acct.Host)
+
+	for _, input := range []string{
+		"",
+		"bob",
+		"@bob",
+		"bob@",
+		"@mastodon.gamedev.place",
+		"acct:@mastodon.gamedev.place",
+		"bob@mastodon.gamedev.place/path",
+		"b/ob@mastodon.gamedev.place",
+		"https://mastodon.gamedev.place/users/bob",
+	} {
+		_, err := apub.ParseAcct(input)
+		assert.True(t, xerrors.Is(err, apub.ErrInvalidAcct), input)
+	}
+}
+
+func TestWebFinger(t *testing.T) {
+	var host string
+	handler := &apub.WebFingerHandler{
+		Lookup: apub.WebFingerLookupFunc(func(ctx context.Context, acct apub.Acct) (*apub.JRD, error) {
+			if acct.User != "bob" || acct.Host != host {
+				return nil, apub.ErrAcctNotFound
+			}
+			return &apub.JRD{
+				Subject: acct.String(),
+				Aliases: []string{"https://" + host + "/@bob"},
+				Links: []*apub.JRDLink{
+					{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: "https://" + host + "/@bob"},
+					{Rel: "self", Type: "application/activity+json", Href: "https://" + host + "/users/bob"},
+					{Rel: "http://ostatus.org/schema/1.0/subscribe", Template: "https://" + host + "/authorize_interaction?uri={uri}"},
+				},
+			}, nil
+		}),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/.well-known/webfinger", handler)
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.Nil(t, err)
+	host = u.Host
+
+	t.Run("handler", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/.well-known/webfinger?resource=acct:bob@"+host+"&rel=self", nil)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "application/jrd+json; charset=utf-8", res.Header().Get("Content-Type"))
+		assert.Equal(t, "*", res.Header().Get("Access-Control-Allow-Origin"))
+
+		jrd := &apub.JRD{}
+		require.Nil(t, json.NewDecoder(res.Body).Decode(jrd))
+		assert.Equal(t, "acct:bob@"+host, jrd.Subject)
+		if assert.Equal(t, 1, len(jrd.Links)) {
+			assert.Equal(t, "self", jrd.Links[0].Rel)
+		}
+
+		for resource, status := range map[string]int{
+			"":                        http.StatusBadRequest,
+			"bob":                     http.StatusBadRequest,
+			"acct:alice@" + host:      http.StatusNotFound,
+			"acct:bob@other.example":  http.StatusNotFound,
+			"acct:bob@" + host + "#x": http.StatusBadRequest,
+		} {
+			req := httptest.NewRequest("GET", "/.well-known/webfinger?resource="+url.QueryEscape(resource), nil)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+			assert.Equal(t, status, res.Code, resource)
+		}
+
+		req = httptest.NewRequest("POST", "/.well-known/webfinger", nil)
+		res = httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, res.Code)
+	})
+
+	t.Run("client", func(t *testing.T) {
+		client := &apub.WebFingerClient{Client: server.Client()}
+		acct, err := apub.ParseAcct("@bob@" + host)
+		require.Nil(t, err)
+
+		jrd, err := client.Lookup(context.Background(), acct)
+		require.Nil(t, err)
+		assert.Equal(t, "acct:bob@"+host, jrd.Subject)
+		assert.Equal(t, 3, len(jrd.Links))
+		assert.Equal(t, "https://"+host+"/@bob", jrd.Link("http://webfinger.net/rel/profile-page", "").Href)
+		assert.Nil(t, jrd.Link("self", "text/html"))
+
+		id, err := client.ActorID(context.Background(), acct)
+		require.Nil(t, err)
+		assert.Equal(t, "https://"+host+"/users/bob", id)
+
+		_, err = client.Lookup(context.Background(), apub.Acct{User: "alice", Host: host})
+		assert.True(t, xerrors.Is(err, apub.ErrAcctNotFound), err)
+
+		jrd.Links = jrd.Links[:1]
+		_, err = jrd.ActorID()
+		assert.True(t, xerrors.Is(err, apub.ErrNoActorLink), err)
+	})
+}